@@ -58,6 +58,48 @@ func (c *WorkwxApp) execJSCode2Session(req reqJSCode2Session) (respJSCode2Sessio
 	return resp, nil
 }
 
+// execGetCallbackIP 获取企业微信回调IP段
+func (c *WorkwxApp) execGetCallbackIP(req reqGetCallbackIP) (respIPList, error) {
+	var resp respIPList
+	err := c.executeQiYeApiGet("/cgi-bin/getcallbackip", req, &resp, true)
+	if err != nil {
+		return respIPList{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respIPList{}, bizErr
+	}
+
+	return resp, nil
+}
+
+// execGetAPIDomainIP 获取企业微信接口IP段
+func (c *WorkwxApp) execGetAPIDomainIP(req reqGetAPIDomainIP) (respIPList, error) {
+	var resp respIPList
+	err := c.executeQiYeApiGet("/cgi-bin/get_api_domain_ip", req, &resp, true)
+	if err != nil {
+		return respIPList{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respIPList{}, bizErr
+	}
+
+	return resp, nil
+}
+
+// execUserCreate 创建成员
+func (c *WorkwxApp) execUserCreate(req reqUserCreate) (respUserCreate, error) {
+	var resp respUserCreate
+	err := c.executeCollyPost("/cgi-bin/user/create", req, &resp, true)
+	if err != nil {
+		return respUserCreate{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respUserCreate{}, bizErr
+	}
+
+	return resp, nil
+}
+
 // execUserGet 读取成员
 func (c *WorkwxApp) execUserGet(req reqUserGet) (respUserGet, error) {
 	var resp respUserGet
@@ -72,6 +114,48 @@ func (c *WorkwxApp) execUserGet(req reqUserGet) (respUserGet, error) {
 	return resp, nil
 }
 
+// execUserUpdate 更新成员
+func (c *WorkwxApp) execUserUpdate(req reqUserUpdate) (respUserUpdate, error) {
+	var resp respUserUpdate
+	err := c.executeCollyPost("/cgi-bin/user/update", req, &resp, true)
+	if err != nil {
+		return respUserUpdate{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respUserUpdate{}, bizErr
+	}
+
+	return resp, nil
+}
+
+// execUserDelete 删除成员
+func (c *WorkwxApp) execUserDelete(req reqUserDelete) (respUserDelete, error) {
+	var resp respUserDelete
+	err := c.executeQiYeApiGet("/cgi-bin/user/delete", req, &resp, true)
+	if err != nil {
+		return respUserDelete{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respUserDelete{}, bizErr
+	}
+
+	return resp, nil
+}
+
+// execUserBatchDelete 批量删除成员
+func (c *WorkwxApp) execUserBatchDelete(req reqUserBatchDelete) (respUserBatchDelete, error) {
+	var resp respUserBatchDelete
+	err := c.executeCollyPost("/cgi-bin/user/batchdelete", req, &resp, true)
+	if err != nil {
+		return respUserBatchDelete{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respUserBatchDelete{}, bizErr
+	}
+
+	return resp, nil
+}
+
 // execUserList 获取部门成员详情
 func (c *WorkwxApp) execUserList(req reqUserList) (respUserList, error) {
 	var resp respUserList
@@ -100,6 +184,48 @@ func (c *WorkwxApp) execUserIDByMobile(req reqUserIDByMobile) (respUserIDByMobil
 	return resp, nil
 }
 
+// execDeptCreate 创建部门
+func (c *WorkwxApp) execDeptCreate(req reqDeptCreate) (respDeptCreate, error) {
+	var resp respDeptCreate
+	err := c.executeCollyPost("/cgi-bin/department/create", req, &resp, true)
+	if err != nil {
+		return respDeptCreate{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respDeptCreate{}, bizErr
+	}
+
+	return resp, nil
+}
+
+// execDeptUpdate 更新部门
+func (c *WorkwxApp) execDeptUpdate(req reqDeptUpdate) (respDeptUpdate, error) {
+	var resp respDeptUpdate
+	err := c.executeCollyPost("/cgi-bin/department/update", req, &resp, true)
+	if err != nil {
+		return respDeptUpdate{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respDeptUpdate{}, bizErr
+	}
+
+	return resp, nil
+}
+
+// execDeptDelete 删除部门
+func (c *WorkwxApp) execDeptDelete(req reqDeptDelete) (respDeptDelete, error) {
+	var resp respDeptDelete
+	err := c.executeQiYeApiGet("/cgi-bin/department/delete", req, &resp, true)
+	if err != nil {
+		return respDeptDelete{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respDeptDelete{}, bizErr
+	}
+
+	return resp, nil
+}
+
 // execDeptList 获取部门列表
 func (c *WorkwxApp) execDeptList(req reqDeptList) (respDeptList, error) {
 	var resp respDeptList
@@ -324,6 +450,20 @@ func (c *WorkwxApp) execAppchatCreate(req reqAppchatCreate) (respAppchatCreate,
 	return resp, nil
 }
 
+// execAppchatUpdate 修改群聊会话
+func (c *WorkwxApp) execAppchatUpdate(req reqAppchatUpdate) (respAppchatUpdate, error) {
+	var resp respAppchatUpdate
+	err := c.executeCollyPost("/cgi-bin/appchat/update", req, &resp, true)
+	if err != nil {
+		return respAppchatUpdate{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respAppchatUpdate{}, bizErr
+	}
+
+	return resp, nil
+}
+
 // execAppchatGet 获取群聊会话
 func (c *WorkwxApp) execAppchatGet(req reqAppchatGet) (respAppchatGet, error) {
 	var resp respAppchatGet
@@ -341,7 +481,6 @@ func (c *WorkwxApp) execAppchatGet(req reqAppchatGet) (respAppchatGet, error) {
 // execMessageSend 发送应用消息
 func (c *WorkwxApp) execMessageSend(req reqMessage) (respMessageSend, error) {
 	var resp respMessageSend
-
 	err := c.executeCollyPost("/cgi-bin/message/send", req, &resp, true)
 	if err != nil {
 		return respMessageSend{}, err
@@ -1,5 +1,12 @@
 package workwx
 
+import (
+	"errors"
+	"sync"
+)
+
+var errAppchatIDRequired = errors.New("appchat: chatid is required")
+
 // CreateAppchat 创建群聊会话
 func (c *WorkwxApp) CreateAppchat(chatInfo *ChatInfo) (chatid string, err error) {
 	resp, err := c.execAppchatCreate(reqAppchatCreate{
@@ -13,6 +20,10 @@ func (c *WorkwxApp) CreateAppchat(chatInfo *ChatInfo) (chatid string, err error)
 
 // GetAppchat 获取群聊会话
 func (c *WorkwxApp) GetAppchat(chatid string) (*ChatInfo, error) {
+	if chatid == "" {
+		return nil, errAppchatIDRequired
+	}
+
 	resp, err := c.execAppchatGet(reqAppchatGet{
 		ChatID: chatid,
 	})
@@ -24,3 +35,129 @@ func (c *WorkwxApp) GetAppchat(chatid string) (*ChatInfo, error) {
 	obj := resp.ChatInfo
 	return obj, nil
 }
+
+// UpdateAppchat 修改群聊会话
+//
+// 只会修改 update 中非零值的字段；ChatID 必填。
+func (c *WorkwxApp) UpdateAppchat(update *AppchatUpdate) error {
+	if update == nil || update.ChatID == "" {
+		return errAppchatIDRequired
+	}
+
+	_, err := c.execAppchatUpdate(reqAppchatUpdate{
+		Update: update,
+	})
+	return err
+}
+
+// Appchat 群聊会话句柄
+//
+// 通过 WorkwxApp.Appchat 获得，缓存了群聊信息，并提供修改群聊、推送消息等操作。
+// 可在多个 goroutine 中并发使用。
+type Appchat struct {
+	app *WorkwxApp
+
+	// ChatID 群聊id
+	ChatID string
+
+	mu   sync.Mutex
+	info *ChatInfo
+}
+
+// Appchat 获取指定群聊会话的句柄
+//
+// 本方法不发起任何请求，群聊信息在首次调用 Info 时获取。
+func (c *WorkwxApp) Appchat(chatid string) *Appchat {
+	return &Appchat{
+		app:    c,
+		ChatID: chatid,
+	}
+}
+
+// Info 获取群聊信息，优先返回缓存
+func (a *Appchat) Info() (*ChatInfo, error) {
+	a.mu.Lock()
+	info := a.info
+	a.mu.Unlock()
+
+	if info != nil {
+		return info, nil
+	}
+
+	return a.Refresh()
+}
+
+// Refresh 重新获取群聊信息并更新缓存
+func (a *Appchat) Refresh() (*ChatInfo, error) {
+	info, err := a.app.GetAppchat(a.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.info = info
+	a.mu.Unlock()
+
+	return info, nil
+}
+
+// Update 修改群聊会话，update 的 ChatID 字段会被忽略
+//
+// 修改成功后缓存的群聊信息会失效，下次调用 Info 时重新获取。
+func (a *Appchat) Update(update AppchatUpdate) error {
+	update.ChatID = a.ChatID
+	err := a.app.UpdateAppchat(&update)
+	if err != nil {
+		return err
+	}
+
+	a.invalidate()
+	return nil
+}
+
+// Rename 修改群聊名
+func (a *Appchat) Rename(name string) error {
+	return a.Update(AppchatUpdate{Name: name})
+}
+
+// SetOwner 修改群主
+func (a *Appchat) SetOwner(userID string) error {
+	return a.Update(AppchatUpdate{OwnerUserID: userID})
+}
+
+// AddMembers 添加群成员
+func (a *Appchat) AddMembers(userIDs ...string) error {
+	return a.Update(AppchatUpdate{AddMemberUserIDs: userIDs})
+}
+
+// RemoveMembers 踢出群成员
+func (a *Appchat) RemoveMembers(userIDs ...string) error {
+	return a.Update(AppchatUpdate{DelMemberUserIDs: userIDs})
+}
+
+func (a *Appchat) invalidate() {
+	a.mu.Lock()
+	a.info = nil
+	a.mu.Unlock()
+}
+
+// Send 推送消息到本群聊
+func (a *Appchat) Send(msg Message, isSafe bool) error {
+	if a.ChatID == "" {
+		return errAppchatIDRequired
+	}
+
+	content, err := msg.intoContent()
+	if err != nil {
+		return err
+	}
+
+	_, err = a.app.execAppchatSend(reqMessage{
+		ChatID:  a.ChatID,
+		AgentID: a.app.AgentID,
+		MsgType: msg.msgType(),
+		Content: content,
+		IsSafe:  isSafe,
+	})
+	return err
+}
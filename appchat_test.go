@@ -0,0 +1,96 @@
+package workwx
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAppchat(t *testing.T) {
+	c.Convey("群聊会话", t, func() {
+		var lastPath string
+		var lastBody map[string]interface{}
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/cgi-bin/gettoken" {
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+				return
+			}
+
+			calls++
+			lastPath = r.URL.Path
+			lastBody = nil
+			body, _ := ioutil.ReadAll(r.Body)
+			if len(body) > 0 {
+				_ = json.Unmarshal(body, &lastBody)
+			}
+			_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}))
+		defer server.Close()
+
+		app := New("ww6a112864f8022910", WithQYAPIHost(server.URL)).WithApp("secret", 1000001)
+
+		c.Convey("修改群聊会话", func() {
+			err := app.UpdateAppchat(&AppchatUpdate{
+				ChatID:           "CHATID",
+				Name:             "NAME",
+				AddMemberUserIDs: []string{"zhangsan", "lisi"},
+				DelMemberUserIDs: []string{"wangwu"},
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/appchat/update")
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"chatid":        "CHATID",
+				"name":          "NAME",
+				"add_user_list": []interface{}{"zhangsan", "lisi"},
+				"del_user_list": []interface{}{"wangwu"},
+			})
+		})
+
+		c.Convey("通过句柄添加、踢出群成员", func() {
+			chat := app.Appchat("CHATID")
+
+			c.So(chat.AddMembers("zhangsan", "lisi"), c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/appchat/update")
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"chatid":        "CHATID",
+				"add_user_list": []interface{}{"zhangsan", "lisi"},
+			})
+
+			c.So(chat.RemoveMembers("wangwu"), c.ShouldBeNil)
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"chatid":        "CHATID",
+				"del_user_list": []interface{}{"wangwu"},
+			})
+		})
+
+		c.Convey("通过句柄推送消息", func() {
+			err := app.Appchat("CHATID").Send(TextMessage{Content: "hello"}, true)
+			c.So(err, c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/appchat/send")
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"chatid":  "CHATID",
+				"agentid": float64(1000001),
+				"msgtype": "text",
+				"safe":    float64(1),
+				"text":    map[string]interface{}{"content": "hello"},
+			})
+		})
+
+		c.Convey("缺少 chatid 时不发起请求", func() {
+			c.So(app.UpdateAppchat(&AppchatUpdate{Name: "NAME"}), c.ShouldEqual, errAppchatIDRequired)
+			c.So(app.UpdateAppchat(nil), c.ShouldEqual, errAppchatIDRequired)
+			_, err := app.GetAppchat("")
+			c.So(err, c.ShouldEqual, errAppchatIDRequired)
+
+			chat := app.Appchat("")
+			c.So(chat.Rename("NAME"), c.ShouldEqual, errAppchatIDRequired)
+			c.So(chat.Send(TextMessage{Content: "hello"}, false), c.ShouldEqual, errAppchatIDRequired)
+			c.So(calls, c.ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,259 @@
+package workwx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetCallbackIP 获取企业微信回调IP段
+//
+// 返回的每一项为 IP 地址或 CIDR 形式的 IP 段。
+func (c *WorkwxApp) GetCallbackIP() ([]string, error) {
+	resp, err := c.execGetCallbackIP(reqGetCallbackIP{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.IPList, nil
+}
+
+// GetAPIDomainIP 获取企业微信接口IP段
+//
+// 返回的每一项为 IP 地址或 CIDR 形式的 IP 段。
+func (c *WorkwxApp) GetAPIDomainIP() ([]string, error) {
+	resp, err := c.execGetAPIDomainIP(reqGetAPIDomainIP{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.IPList, nil
+}
+
+// parseIPNets 将 IP 地址或 CIDR 形式的 IP 段列表解析为 *net.IPNet
+func parseIPNets(l []string) ([]*net.IPNet, error) {
+	result := make([]*net.IPNet, 0, len(l))
+	for _, x := range l {
+		x = strings.TrimSpace(x)
+		if x == "" {
+			continue
+		}
+
+		if strings.Contains(x, "/") {
+			_, n, err := net.ParseCIDR(x)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, n)
+			continue
+		}
+
+		ip := net.ParseIP(x)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address: %q", x)
+		}
+		bits := 8 * net.IPv4len
+		if ip.To4() == nil {
+			bits = 8 * net.IPv6len
+		}
+		result = append(result, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return result, nil
+}
+
+func ipNetsContain(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+//
+//
+//
+
+type callbackIPAllowlistOptions struct {
+	proxyHeader    string
+	trustedProxies []string
+}
+
+// CallbackIPAllowlistOption 回调来源 IP 白名单构造参数
+type CallbackIPAllowlistOption interface {
+	applyTo(*callbackIPAllowlistOptions)
+}
+
+type withCallbackIPTrustedProxies struct {
+	header  string
+	proxies []string
+}
+
+// WithCallbackIPTrustedProxies 信任来自 proxies（IP 地址或 CIDR）的反向代理所设置的 header 请求头
+//
+// header 一般为 "X-Forwarded-For" 或 "X-Real-IP"。请求的直接来源属于 proxies 时，
+// 会从右向左跳过 header 中同样属于 proxies 的地址，以第一个不属于 proxies 的地址
+// 作为回调来源。默认不信任任何代理，直接以 TCP 连接的对端地址作为回调来源。
+func WithCallbackIPTrustedProxies(header string, proxies ...string) CallbackIPAllowlistOption {
+	return &withCallbackIPTrustedProxies{header: header, proxies: proxies}
+}
+
+func (x *withCallbackIPTrustedProxies) applyTo(y *callbackIPAllowlistOptions) {
+	y.proxyHeader = x.header
+	y.trustedProxies = x.proxies
+}
+
+// CallbackIPAllowlist 基于企业微信回调IP段的回调来源白名单
+//
+// 配合 WithCallbackIPAllowlist 使用。IP 段需通过 Refresh 或
+// SpawnRefresherWithContext 从企业微信获取；获取成功之前，所有回调都会被拒绝。
+type CallbackIPAllowlist struct {
+	app            *WorkwxApp
+	proxyHeader    string
+	trustedProxies []*net.IPNet
+
+	mu      sync.RWMutex
+	allowed []*net.IPNet
+}
+
+// NewCallbackIPAllowlist 构造一个以 app 获取回调IP段的来源白名单
+func NewCallbackIPAllowlist(app *WorkwxApp, opts ...CallbackIPAllowlistOption) (*CallbackIPAllowlist, error) {
+	var options callbackIPAllowlistOptions
+	for _, o := range opts {
+		o.applyTo(&options)
+	}
+
+	trustedProxies, err := parseIPNets(options.trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CallbackIPAllowlist{
+		app:            app,
+		proxyHeader:    http.CanonicalHeaderKey(options.proxyHeader),
+		trustedProxies: trustedProxies,
+	}, nil
+}
+
+// Refresh 重新获取企业微信回调IP段
+func (a *CallbackIPAllowlist) Refresh() error {
+	l, err := a.app.GetCallbackIP()
+	if err != nil {
+		return err
+	}
+	return a.set(l)
+}
+
+func (a *CallbackIPAllowlist) set(l []string) error {
+	nets, err := parseIPNets(l)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.allowed = nets
+	return nil
+}
+
+// SpawnRefresherWithContext 启动每隔 interval 刷新一次回调IP段的 goroutine
+// 可以通过 context cancellation 停止此 goroutine
+//
+// 启动时会立即刷新一次。刷新失败时保留之前的IP段，并以 logrus 记录错误。
+// interval 必须为正数，否则返回错误且不启动 goroutine。
+func (a *CallbackIPAllowlist) SpawnRefresherWithContext(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("callback IP refresh interval must be positive, got %v", interval)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := a.Refresh(); err != nil {
+				logrus.Errorf("failed to refresh callback IP allowlist: %+v", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Allows 判断 ip 是否属于企业微信回调IP段
+func (a *CallbackIPAllowlist) Allows(ip net.IP) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return ipNetsContain(a.allowed, ip)
+}
+
+// AllowsRequest 判断 r 是否来自企业微信回调IP段，会按配置考虑受信任的反向代理
+func (a *CallbackIPAllowlist) AllowsRequest(r *http.Request) bool {
+	ip := a.sourceIP(r)
+	if ip == nil {
+		return false
+	}
+	return a.Allows(ip)
+}
+
+func (a *CallbackIPAllowlist) sourceIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+
+	if a.proxyHeader == "" || !ipNetsContain(a.trustedProxies, ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, v := range r.Header[a.proxyHeader] {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			return nil
+		}
+		if !ipNetsContain(a.trustedProxies, hop) {
+			return hop
+		}
+	}
+
+	// every hop is a trusted proxy
+	return ip
+}
+
+//
+//
+//
+
+type withCallbackIPAllowlist struct {
+	x *CallbackIPAllowlist
+}
+
+// WithCallbackIPAllowlist 拒绝来源不在企业微信回调IP段内的回调请求，以 403 响应
+//
+// 默认不检查回调来源。
+func WithCallbackIPAllowlist(a *CallbackIPAllowlist) HTTPHandlerOption {
+	return &withCallbackIPAllowlist{x: a}
+}
+
+var _ HTTPHandlerOption = (*withCallbackIPAllowlist)(nil)
+
+func (x *withCallbackIPAllowlist) applyTo(y *httpHandlerOptions) {
+	y.ipAllowlist = x.x
+}
@@ -0,0 +1,178 @@
+package workwx
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCallbackIPAllowlist(t *testing.T) {
+	c.Convey("回调来源 IP 白名单", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/cgi-bin/gettoken":
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			case "/cgi-bin/getcallbackip":
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","ip_list":["101.226.103.0/25","182.254.11.176"]}`))
+			case "/cgi-bin/get_api_domain_ip":
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","ip_list":["182.254.11.176","182.254.78.66"]}`))
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		app := New("ww6a112864f8022910", WithQYAPIHost(server.URL)).WithApp("secret", 1000001)
+
+		c.Convey("获取回调IP段与接口IP段", func() {
+			l, err := app.GetCallbackIP()
+			c.So(err, c.ShouldBeNil)
+			c.So(l, c.ShouldResemble, []string{"101.226.103.0/25", "182.254.11.176"})
+
+			l, err = app.GetAPIDomainIP()
+			c.So(err, c.ShouldBeNil)
+			c.So(l, c.ShouldResemble, []string{"182.254.11.176", "182.254.78.66"})
+		})
+
+		c.Convey("刷新前拒绝所有来源", func() {
+			a, err := NewCallbackIPAllowlist(app)
+			c.So(err, c.ShouldBeNil)
+			c.So(a.Allows(net.ParseIP("182.254.11.176")), c.ShouldBeFalse)
+		})
+
+		c.Convey("按 IP 段判断来源", func() {
+			a, err := NewCallbackIPAllowlist(app)
+			c.So(err, c.ShouldBeNil)
+			c.So(a.Refresh(), c.ShouldBeNil)
+
+			c.So(a.Allows(net.ParseIP("101.226.103.1")), c.ShouldBeTrue)
+			c.So(a.Allows(net.ParseIP("182.254.11.176")), c.ShouldBeTrue)
+			c.So(a.Allows(net.ParseIP("101.226.103.200")), c.ShouldBeFalse)
+			c.So(a.Allows(net.ParseIP("182.254.11.177")), c.ShouldBeFalse)
+		})
+
+		c.Convey("受信任的反向代理", func() {
+			a, err := NewCallbackIPAllowlist(
+				app,
+				WithCallbackIPTrustedProxies("X-Forwarded-For", "10.0.0.0/8"),
+			)
+			c.So(err, c.ShouldBeNil)
+			c.So(a.Refresh(), c.ShouldBeNil)
+
+			req := func(remoteAddr string, xff ...string) *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/cb", nil)
+				r.RemoteAddr = remoteAddr
+				for _, v := range xff {
+					r.Header.Add("X-Forwarded-For", v)
+				}
+				return r
+			}
+
+			c.So(a.AllowsRequest(req("10.0.0.1:1234", "182.254.11.176")), c.ShouldBeTrue)
+			c.So(a.AllowsRequest(req("10.0.0.1:1234", "182.254.11.176, 10.0.0.2")), c.ShouldBeTrue)
+			// the left-most hop is client-controlled and must be ignored
+			c.So(a.AllowsRequest(req("10.0.0.1:1234", "182.254.11.176, 1.2.3.4")), c.ShouldBeFalse)
+			// headers from untrusted peers are ignored
+			c.So(a.AllowsRequest(req("1.2.3.4:1234", "182.254.11.176")), c.ShouldBeFalse)
+			c.So(a.AllowsRequest(req("182.254.11.176:1234")), c.ShouldBeTrue)
+		})
+
+		c.Convey("非法的代理地址应该报错", func() {
+			_, err := NewCallbackIPAllowlist(app, WithCallbackIPTrustedProxies("X-Real-IP", "not-an-ip"))
+			c.So(err, c.ShouldNotBeNil)
+		})
+
+		c.Convey("HTTPHandler 拒绝白名单以外的来源", func() {
+			a, err := NewCallbackIPAllowlist(app)
+			c.So(err, c.ShouldBeNil)
+			c.So(a.Refresh(), c.ShouldBeNil)
+
+			called := false
+			h, err := NewHTTPHandler(
+				"kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+				"4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+				RxMessageHandlerFunc(func(_ context.Context, _ *RxMessage) (RxReply, error) {
+					called = true
+					return nil, nil
+				}),
+				WithCallbackIPAllowlist(a),
+			)
+			c.So(err, c.ShouldBeNil)
+
+			r := httptest.NewRequest(http.MethodPost, "/cb", nil)
+			r.RemoteAddr = "1.2.3.4:1234"
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			c.So(rec.Code, c.ShouldEqual, http.StatusForbidden)
+			c.So(called, c.ShouldBeFalse)
+
+			r = httptest.NewRequest(http.MethodPost, "/cb", nil)
+			r.RemoteAddr = "182.254.11.176:1234"
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			c.So(rec.Code, c.ShouldNotEqual, http.StatusForbidden)
+		})
+
+		c.Convey("CallbackMux 在读取请求体前拒绝白名单以外的来源", func() {
+			a, err := NewCallbackIPAllowlist(app)
+			c.So(err, c.ShouldBeNil)
+			c.So(a.Refresh(), c.ShouldBeNil)
+
+			h, err := NewHTTPHandler(
+				"kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+				"4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+				RxMessageHandlerFunc(func(_ context.Context, _ *RxMessage) (RxReply, error) {
+					return nil, nil
+				}),
+			)
+			c.So(err, c.ShouldBeNil)
+			mux := NewCallbackMux(WithCallbackMuxIPAllowlist(a))
+			mux.HandleAgentID(1000001, h)
+
+			body := &countingReader{r: strings.NewReader("<xml><AgentID>1000001</AgentID></xml>")}
+			r := httptest.NewRequest(http.MethodPost, "/cb", body)
+			r.RemoteAddr = "1.2.3.4:1234"
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, r)
+			c.So(rec.Code, c.ShouldEqual, http.StatusForbidden)
+			c.So(body.n, c.ShouldEqual, 0)
+
+			r = httptest.NewRequest(http.MethodPost, "/cb", body)
+			r.RemoteAddr = "182.254.11.176:1234"
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, r)
+			c.So(rec.Code, c.ShouldNotEqual, http.StatusForbidden)
+			c.So(body.n, c.ShouldBeGreaterThan, 0)
+		})
+
+		c.Convey("刷新间隔必须为正数", func() {
+			a, err := NewCallbackIPAllowlist(app)
+			c.So(err, c.ShouldBeNil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c.So(a.SpawnRefresherWithContext(ctx, 0), c.ShouldNotBeNil)
+			c.So(a.SpawnRefresherWithContext(ctx, -time.Second), c.ShouldNotBeNil)
+			c.So(a.SpawnRefresherWithContext(ctx, time.Hour), c.ShouldBeNil)
+		})
+	})
+}
+
+// countingReader 记录被读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (x *countingReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	x.n += n
+	return n, err
+}
@@ -0,0 +1,130 @@
+// Package callbackcrypto 提供企业微信回调消息的验签、解密与加密。
+//
+// 本包不依赖 net/http，适用于云函数、消息队列消费者等自行接收原始回调请求的场景；
+// 普通 HTTP 服务直接使用 workwx.HTTPHandler 即可。解密所得的明文 XML 可用
+// workwx.ParseRxMessage 解析，被动回复可用 workwx.MarshalRxReply 组装。
+package callbackcrypto
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
+)
+
+// ErrInvalidSignature 回调请求签名校验失败
+var ErrInvalidSignature = envelope.ErrInvalidSignature
+
+// ErrMalformedRequest 回调请求缺少必要的参数，或参数格式不正确
+var ErrMalformedRequest = errors.New("malformed callback request")
+
+// ReceiveIDMismatchError 回调消息解密后的 ReceiveID 与预期不符
+type ReceiveIDMismatchError = envelope.ReceiveIDMismatchError
+
+type options struct {
+	envelopeOpts []envelope.ProcessorOption
+}
+
+// Option Crypto 构造参数
+type Option interface {
+	applyTo(*options)
+}
+
+type withReceiveIDs struct {
+	x []string
+}
+
+// WithReceiveIDs 拒绝解密后 ReceiveID 不在 ids 之中的回调消息
+//
+// 自建应用的 ReceiveID 为 CorpID，第三方应用的指令回调则为 SuiteID。默认不检查。
+func WithReceiveIDs(ids ...string) Option {
+	return &withReceiveIDs{x: ids}
+}
+
+func (x *withReceiveIDs) applyTo(y *options) {
+	y.envelopeOpts = append(y.envelopeOpts, envelope.WithReceiveIDs(x.x...))
+}
+
+// Crypto 一组回调配置（Token 与 EncodingAESKey）对应的加解密器
+//
+// 本类型不保存任何请求相关的状态，可被并发使用。
+type Crypto struct {
+	p *envelope.Processor
+}
+
+// New 以回调配置中的 Token 与 EncodingAESKey 构造一个 Crypto
+func New(token string, encodingAESKey string, opts ...Option) (*Crypto, error) {
+	var o options
+	for _, opt := range opts {
+		opt.applyTo(&o)
+	}
+
+	p, err := envelope.NewProcessor(token, encodingAESKey, o.envelopeOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Crypto{p: p}, nil
+}
+
+// Message 验签并解密后的一条回调消息
+type Message struct {
+	// ToUserName 明文信封中的接收方，一般为 CorpID
+	ToUserName string
+	// AgentID 明文信封中的应用 ID，通讯录回调等不带此字段
+	AgentID string
+	// Timestamp 请求参数中的时间戳，如需防重放可自行检查
+	Timestamp int64
+	// Nonce 请求参数中的随机数，如需防重放可自行检查
+	Nonce string
+	// XML 解密后的明文 XML 消息体
+	XML []byte
+	// ReceiveID 解密后的 ReceiveID
+	ReceiveID string
+}
+
+// Decrypt 验签并解密一条回调消息
+//
+// query 为回调请求的 URL 参数（含 msg_signature、timestamp 与 nonce），
+// body 为回调请求的请求体。
+func (c *Crypto) Decrypt(query url.Values, body []byte) (*Message, error) {
+	ts, err := strconv.ParseInt(query.Get("timestamp"), 10, 64)
+	if err != nil {
+		return nil, ErrMalformedRequest
+	}
+
+	ev, err := c.p.HandleIncomingMsg(&url.URL{RawQuery: query.Encode()}, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		ToUserName: ev.ToUserName,
+		AgentID:    ev.AgentID,
+		Timestamp:  ts,
+		Nonce:      query.Get("nonce"),
+		XML:        ev.Msg,
+		ReceiveID:  string(ev.ReceiveID),
+	}, nil
+}
+
+// VerifyEcho 验签并解密回调 URL 验证请求的 echostr
+//
+// query 为验证请求的 URL 参数（含 msg_signature、timestamp、nonce 与 echostr），
+// 返回值应原样作为响应体返回给企业微信。
+func (c *Crypto) VerifyEcho(query url.Values) ([]byte, error) {
+	msg, err := c.p.HandleEchoTest(&url.URL{RawQuery: query.Encode()})
+	if err == envelope.ErrMalformedEchoStr {
+		return nil, ErrMalformedRequest
+	}
+	return msg, err
+}
+
+// EncryptReply 加密并签名一条被动回复，返回可直接作为响应体的 XML
+//
+// msg 为被动回复的明文 XML；receiveID 应为所回复消息的 ReceiveID（即
+// Message.ReceiveID）；timestamp 与 nonce 由调用方给出，会原样出现在回复中。
+func (c *Crypto) EncryptReply(msg []byte, receiveID string, timestamp int64, nonce string) ([]byte, error) {
+	return c.p.MakeOutgoingEnvelopeAt(msg, []byte(receiveID), timestamp, nonce)
+}
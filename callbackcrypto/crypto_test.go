@@ -0,0 +1,129 @@
+package callbackcrypto
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+// nolint: gosec  // randomly generated for test purposes only
+const (
+	testToken          = "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme"
+	testEncodingAESKey = "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws"
+	testCorpID         = "ww6a112864f8022910"
+)
+
+type testEnvelope struct {
+	Encrypt      string `xml:"Encrypt"`
+	MsgSignature string `xml:"MsgSignature"`
+	Timestamp    int64  `xml:"Timestamp"`
+	Nonce        string `xml:"Nonce"`
+}
+
+// makeRequest 以 EncryptReply 模拟企业微信发出的回调请求
+func makeRequest(cr *Crypto, msg string, ts int64, nonce string) (url.Values, []byte) {
+	out, err := cr.EncryptReply([]byte(msg), testCorpID, ts, nonce)
+	c.So(err, c.ShouldBeNil)
+
+	var x testEnvelope
+	c.So(xml.Unmarshal(out, &x), c.ShouldBeNil)
+
+	q := url.Values{}
+	q.Set("msg_signature", x.MsgSignature)
+	q.Set("timestamp", fmt.Sprint(x.Timestamp))
+	q.Set("nonce", x.Nonce)
+
+	body := fmt.Sprintf(
+		"<xml><ToUserName>%s</ToUserName><AgentID>1000002</AgentID><Encrypt>%s</Encrypt></xml>",
+		testCorpID,
+		x.Encrypt,
+	)
+	return q, []byte(body)
+}
+
+func TestCrypto(t *testing.T) {
+	c.Convey("回调消息加解密", t, func() {
+		cr, err := New(testToken, testEncodingAESKey)
+		c.So(err, c.ShouldBeNil)
+
+		c.Convey("被动回复带有给定的时间戳与 nonce", func() {
+			out, err := cr.EncryptReply([]byte("<xml></xml>"), testCorpID, 1583995625, "1234567890")
+			c.So(err, c.ShouldBeNil)
+
+			var x testEnvelope
+			c.So(xml.Unmarshal(out, &x), c.ShouldBeNil)
+			c.So(x.Timestamp, c.ShouldEqual, 1583995625)
+			c.So(x.Nonce, c.ShouldEqual, "1234567890")
+		})
+
+		c.Convey("解密回调消息", func() {
+			q, body := makeRequest(
+				cr,
+				"<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>",
+				1583995625,
+				"1234567890",
+			)
+
+			m, err := cr.Decrypt(q, body)
+			c.So(err, c.ShouldBeNil)
+			c.So(m.ToUserName, c.ShouldEqual, testCorpID)
+			c.So(m.AgentID, c.ShouldEqual, "1000002")
+			c.So(m.Timestamp, c.ShouldEqual, 1583995625)
+			c.So(m.Nonce, c.ShouldEqual, "1234567890")
+			c.So(m.ReceiveID, c.ShouldEqual, testCorpID)
+			c.So(string(m.XML), c.ShouldContainSubstring, "<Content><![CDATA[x123]]></Content>")
+		})
+
+		c.Convey("签名错误的回调消息应该被拒绝", func() {
+			q, body := makeRequest(cr, "<xml></xml>", 1583995625, "1234567890")
+			q.Set("nonce", "tampered")
+
+			_, err := cr.Decrypt(q, body)
+			c.So(err, c.ShouldEqual, ErrInvalidSignature)
+		})
+
+		c.Convey("缺少时间戳的回调消息应该被拒绝", func() {
+			q, body := makeRequest(cr, "<xml></xml>", 1583995625, "1234567890")
+			q.Del("timestamp")
+
+			_, err := cr.Decrypt(q, body)
+			c.So(err, c.ShouldEqual, ErrMalformedRequest)
+		})
+
+		c.Convey("ReceiveID 不符的回调消息应该被拒绝", func() {
+			pinned, err := New(testToken, testEncodingAESKey, WithReceiveIDs("wwsomeoneelse"))
+			c.So(err, c.ShouldBeNil)
+
+			q, body := makeRequest(cr, "<xml></xml>", 1583995625, "1234567890")
+			_, err = pinned.Decrypt(q, body)
+			var e *ReceiveIDMismatchError
+			c.So(errors.As(err, &e), c.ShouldBeTrue)
+			c.So(e.Actual, c.ShouldEqual, testCorpID)
+		})
+
+		c.Convey("URL 验证请求", func() {
+			out, err := cr.EncryptReply([]byte("echo-me"), testCorpID, 1583995625, "1234567890")
+			c.So(err, c.ShouldBeNil)
+			var x testEnvelope
+			c.So(xml.Unmarshal(out, &x), c.ShouldBeNil)
+
+			q := url.Values{}
+			q.Set("msg_signature", x.MsgSignature)
+			q.Set("timestamp", fmt.Sprint(x.Timestamp))
+			q.Set("nonce", x.Nonce)
+			q.Set("echostr", x.Encrypt)
+
+			echo, err := cr.VerifyEcho(q)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(echo), c.ShouldEqual, "echo-me")
+
+			q.Set("nonce", "tampered")
+			_, err = cr.VerifyEcho(q)
+			c.So(err, c.ShouldEqual, ErrInvalidSignature)
+		})
+	})
+}
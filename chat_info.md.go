@@ -13,3 +13,17 @@ type ChatInfo struct {
 	// MemberUserIDs 群成员id列表
 	MemberUserIDs []string `json:"userlist"`
 }
+
+// AppchatUpdate 群聊会话修改参数
+type AppchatUpdate struct {
+	// ChatID 群聊id
+	ChatID string `json:"chatid"`
+	// Name 新的群聊名。若不需更新，请忽略此参数。最多50个utf8字符，超过将截断
+	Name string `json:"name,omitempty"`
+	// OwnerUserID 新群主的id。若不需更新，请忽略此参数。课程群聊群主必须在设置的群主列表内
+	OwnerUserID string `json:"owner,omitempty"`
+	// AddMemberUserIDs 添加成员的id列表
+	AddMemberUserIDs []string `json:"add_user_list,omitempty"`
+	// DelMemberUserIDs 踢出成员的id列表
+	DelMemberUserIDs []string `json:"del_user_list,omitempty"`
+}
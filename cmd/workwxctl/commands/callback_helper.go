@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
+)
+
+// callbackDefaultCorpID 未指定 corpid 时模拟回调所用的企业 ID
+const callbackDefaultCorpID = "wwworkwxctl"
+
+// callbackCredentialFlags 回调配置参数
+func callbackCredentialFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     flagCallbackToken,
+			Usage:    "回调配置的 `TOKEN`",
+			EnvVars:  []string{"WORKWXCTL_CALLBACK_TOKEN"},
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     flagCallbackEncodingAESKey,
+			Aliases:  []string{flagCallbackEncodingAESKeyShort},
+			Usage:    "回调配置的 `KEY` (EncodingAESKey)",
+			EnvVars:  []string{"WORKWXCTL_CALLBACK_ENCODING_AES_KEY"},
+			Required: true,
+		},
+	}
+}
+
+// callbackCommonFlags 模拟回调的各子命令共用的参数
+func callbackCommonFlags() []cli.Flag {
+	return append(
+		[]cli.Flag{
+			&cli.StringFlag{
+				Name:     flagURL,
+				Usage:    "回调地址 `URL`，如 http://localhost:8080/callback",
+				Required: true,
+			},
+		},
+		callbackCredentialFlags()...,
+	)
+}
+
+type callbackOptions struct {
+	URL    string
+	CorpID string
+
+	processor *envelope.Processor
+}
+
+func mustGetCallbackConfig(c *cli.Context) *callbackOptions {
+	pr, err := envelope.NewProcessor(
+		c.String(flagCallbackToken),
+		c.String(flagCallbackEncodingAESKey),
+	)
+	if err != nil {
+		fmt.Printf("invalid callback token or key: %+v\n", err)
+		panic(err)
+	}
+
+	corpID := c.String(flagCorpID)
+	if corpID == "" {
+		corpID = callbackDefaultCorpID
+	}
+
+	return &callbackOptions{
+		URL:       c.String(flagURL),
+		CorpID:    corpID,
+		processor: pr,
+	}
+}
+
+type callbackTxEnvelope struct {
+	Encrypt      string `xml:"Encrypt"`
+	MsgSignature string `xml:"MsgSignature"`
+	Timestamp    int64  `xml:"Timestamp"`
+	Nonce        string `xml:"Nonce"`
+}
+
+// seal 像企业微信一样加密、签名 msg，返回请求参数与 Encrypt 字段
+func (c *callbackOptions) seal(msg []byte) (url.Values, string, error) {
+	out, err := c.processor.MakeOutgoingEnvelopeWithReceiveID(msg, []byte(c.CorpID))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var x callbackTxEnvelope
+	err = xml.Unmarshal(out, &x)
+	if err != nil {
+		return nil, "", err
+	}
+
+	q := url.Values{}
+	q.Set("msg_signature", x.MsgSignature)
+	q.Set("timestamp", strconv.FormatInt(x.Timestamp, 10))
+	q.Set("nonce", x.Nonce)
+	return q, x.Encrypt, nil
+}
+
+func (c *callbackOptions) makeURL(q url.Values) (string, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return "", err
+	}
+
+	orig := u.Query()
+	for k, v := range q {
+		orig[k] = v
+	}
+	u.RawQuery = orig.Encode()
+	return u.String(), nil
+}
+
+// openReply 验签并解密被动回复
+func (c *callbackOptions) openReply(body []byte) ([]byte, error) {
+	var x callbackTxEnvelope
+	err := xml.Unmarshal(body, &x)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("msg_signature", x.MsgSignature)
+	q.Set("timestamp", strconv.FormatInt(x.Timestamp, 10))
+	q.Set("nonce", x.Nonce)
+
+	ev, err := c.processor.HandleIncomingMsg(&url.URL{RawQuery: q.Encode()}, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(ev.ReceiveID) != c.CorpID {
+		return nil, fmt.Errorf("unexpected ReceiveID %q in passive reply", ev.ReceiveID)
+	}
+	return ev.Msg, nil
+}
+
+func readResponse(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return body, fmt.Errorf("callback responded with %s: %s", resp.Status, msg)
+		}
+		return body, fmt.Errorf("callback responded with %s", resp.Status)
+	}
+	return body, nil
+}
+
+// unescapeJSONHTML 还原 json.Marshal 对 <、>、& 的转义
+//
+// 只处理字符串中的 \u003c、\u003e 与 \u0026，其余转义序列原样保留。
+func unescapeJSONHTML(b []byte) []byte {
+	result := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 >= len(b) {
+			result = append(result, b[i])
+			continue
+		}
+
+		if b[i+1] == 'u' && i+6 <= len(b) {
+			switch string(b[i+2 : i+6]) {
+			case "003c":
+				result = append(result, '<')
+				i += 5
+				continue
+			case "003e":
+				result = append(result, '>')
+				i += 5
+				continue
+			case "0026":
+				result = append(result, '&')
+				i += 5
+				continue
+			}
+		}
+
+		// other escapes are copied as a whole, so that "\\u003c" stays intact
+		result = append(result, b[i], b[i+1])
+		i++
+	}
+	return result
+}
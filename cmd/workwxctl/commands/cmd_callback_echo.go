@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/urfave/cli/v2"
+)
+
+var errCallbackEchoMismatch = errors.New("callback responded with a wrong echostr")
+
+func cmdCallbackEcho(c *cli.Context) error {
+	cfg := mustGetCallbackConfig(c)
+
+	var buf [16]byte
+	_, err := rand.Read(buf[:])
+	if err != nil {
+		return err
+	}
+	echoStr := hex.EncodeToString(buf[:])
+
+	q, encrypt, err := cfg.seal([]byte(echoStr))
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+		return err
+	}
+	q.Set("echostr", encrypt)
+
+	target, err := cfg.makeURL(q)
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+		return err
+	}
+
+	resp, err := http.Get(target)
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+		return err
+	}
+
+	body, err := readResponse(resp)
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+		return err
+	}
+
+	if string(body) != echoStr {
+		fmt.Printf("error = %+v: want %q, got %q\n", errCallbackEchoMismatch, echoStr, body)
+		return errCallbackEchoMismatch
+	}
+
+	fmt.Println("echo verification passed")
+	return nil
+}
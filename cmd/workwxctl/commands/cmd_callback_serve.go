@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/xen0n/go-workwx"
+)
+
+func cmdCallbackServe(c *cli.Context) error {
+	addr := c.String(flagCallbackAddr)
+	asJSON := c.Bool(flagCallbackJSON)
+	recordPath := c.String(flagCallbackRecord)
+
+	sinks := []workwx.RxSink{newCallbackPrintSink(asJSON)}
+	if recordPath != "" {
+		rec, err := workwx.NewRxJSONLSink(recordPath, workwx.WithRxJSONLMaxBytes(0))
+		if err != nil {
+			fmt.Printf("error = %+v\n", err)
+			return err
+		}
+		defer rec.Close()
+		sinks = append(sinks, rec)
+	}
+
+	hh, err := workwx.NewHTTPHandler(
+		c.String(flagCallbackToken),
+		c.String(flagCallbackEncodingAESKey),
+		workwx.NewRxFanoutHandler(sinks...),
+	)
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+		return err
+	}
+
+	if !asJSON {
+		fmt.Printf("listening on %s\n", addr)
+	}
+	err = http.ListenAndServe(addr, hh)
+	if err != nil {
+		fmt.Printf("error = %+v\n", err)
+	}
+	return err
+}
+
+// newCallbackPrintSink 构造一个向标准输出打印消息的 RxSink
+func newCallbackPrintSink(asJSON bool) workwx.RxSink {
+	var mu sync.Mutex
+	return workwx.RxSinkFunc(func(_ context.Context, msg *workwx.RxMessage) error {
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		// keep raw_xml readable, instead of escaping every angle bracket
+		b = unescapeJSONHTML(b)
+
+		var buf bytes.Buffer
+		if asJSON {
+			buf.Write(b)
+		} else {
+			err = json.Indent(&buf, b, "", "  ")
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('\n')
+
+		// requests are served concurrently
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !asJSON {
+			kind := string(msg.MsgType)
+			if msg.Event != "" {
+				kind += "/" + string(msg.Event)
+			}
+			fmt.Printf(
+				"=== %s %s from %q\n",
+				msg.SendTime.Format("2006-01-02 15:04:05"),
+				kind,
+				msg.FromUserID,
+			)
+		}
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	})
+}
@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 
@@ -28,6 +31,11 @@ func cmdSendMessage(c *cli.Context) error {
 	buttonText := c.String(flagButtonText)
 	sourceContentURL := c.String(flagSourceContentURL)
 	digest := c.String(flagDigest)
+	articlesFile := c.String(flagArticlesFile)
+	miniprogramAppID := c.String(flagMiniprogramAppID)
+	miniprogramPage := c.String(flagMiniprogramPage)
+	emphasisFirstItem := c.Bool(flagEmphasisFirstItem)
+	contentKVs := c.StringSlice(flagMiniprogramContentKV)
 
 	app := cfg.MakeWorkwxApp()
 
@@ -71,6 +79,16 @@ func cmdSendMessage(c *cli.Context) error {
 			isSafe,
 		)
 	case "news":
+		if articlesFile != "" {
+			var articles []workwx.NewsArticle
+			err = readArticlesFile(articlesFile, &articles)
+			if err != nil {
+				return err
+			}
+			err = app.SendNewsArticles(&recipient, articles, isSafe)
+			break
+		}
+
 		err = app.SendNewsMessage(
 			&recipient,
 			title,
@@ -80,6 +98,16 @@ func cmdSendMessage(c *cli.Context) error {
 			isSafe,
 		)
 	case "mpnews":
+		if articlesFile != "" {
+			var articles []workwx.MPNewsArticle
+			err = readArticlesFile(articlesFile, &articles)
+			if err != nil {
+				return err
+			}
+			err = app.SendMPNewsArticles(&recipient, articles, isSafe)
+			break
+		}
+
 		err = app.SendMPNewsMessage(
 			&recipient,
 			title,
@@ -90,6 +118,22 @@ func cmdSendMessage(c *cli.Context) error {
 			digest,
 			isSafe,
 		)
+	case "miniprogram_notice":
+		var contentItems []workwx.MiniprogramNoticeContentItem
+		contentItems, err = parseMiniprogramContentItems(contentKVs)
+		if err != nil {
+			return err
+		}
+
+		err = app.SendMiniprogramNoticeMessage(
+			&recipient,
+			miniprogramAppID,
+			miniprogramPage,
+			title,
+			description,
+			emphasisFirstItem,
+			contentItems,
+		)
 	default:
 		fmt.Printf("unrecognized message type: %s\n", msgtype)
 		panic("unrecognized message type")
@@ -97,3 +141,31 @@ func cmdSendMessage(c *cli.Context) error {
 
 	return err
 }
+
+// readArticlesFile 从 JSON 文件读取图文列表，文件内容为图文对象的数组
+func readArticlesFile(path string, articles interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, articles)
+}
+
+// parseMiniprogramContentItems 解析形如 KEY=VALUE 的小程序通知消息内容行
+func parseMiniprogramContentItems(kvs []string) ([]workwx.MiniprogramNoticeContentItem, error) {
+	result := make([]workwx.MiniprogramNoticeContentItem, len(kvs))
+	for i, kv := range kvs {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed content item (want KEY=VALUE): %s", kv)
+		}
+
+		result[i] = workwx.MiniprogramNoticeContentItem{
+			Key:   parts[0],
+			Value: parts[1],
+		}
+	}
+
+	return result, nil
+}
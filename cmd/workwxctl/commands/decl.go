@@ -64,7 +64,7 @@ func InitApp() *cli.App {
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:  flagMessageType,
-						Usage: "发送消息的类型: text, image, voice, video, file, textcard, news, mpnews, markdown",
+						Usage: "发送消息的类型: text, image, voice, video, file, textcard, news, mpnews, markdown, miniprogram_notice",
 					},
 					&cli.StringSliceFlag{
 						Name:    flagToUser,
@@ -132,6 +132,26 @@ func InitApp() *cli.App {
 						Name:  flagDigest,
 						Usage: "图文消息的描述，不超过512个字节，超过会自动截断",
 					},
+					&cli.StringFlag{
+						Name:  flagArticlesFile,
+						Usage: "从 JSON 文件 `FILE` 读取图文列表（news、mpnews 类型，最多 8 篇），指定时忽略单篇图文的各项参数",
+					},
+					&cli.StringFlag{
+						Name:  flagMiniprogramAppID,
+						Usage: "小程序通知消息的小程序 `APPID`，必须是与当前应用关联的小程序",
+					},
+					&cli.StringFlag{
+						Name:  flagMiniprogramPage,
+						Usage: "点击小程序通知消息后跳转的小程序页面 `PAGE`，仅限本小程序内的页面",
+					},
+					&cli.BoolFlag{
+						Name:  flagEmphasisFirstItem,
+						Usage: "小程序通知消息是否放大第一个 content_item",
+					},
+					&cli.StringSliceFlag{
+						Name:  flagMiniprogramContentKV,
+						Usage: "小程序通知消息的一行消息内容，格式为 `KEY=VALUE` (可指定多次，最多 10 行)",
+					},
 				},
 			},
 			{
@@ -145,6 +165,73 @@ func InitApp() *cli.App {
 					},
 				},
 			},
+			{
+				Name:  "callback",
+				Usage: "回调开发调试工具：模拟企业微信发送回调，或接收并打印回调 (corpid 与 agentid 可选)",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "send",
+						Usage:     "加密、签名并发送一条回调消息，打印解密后的被动回复",
+						ArgsUsage: "[CONTENT]",
+						Action:    cmdCallbackSend,
+						Flags: append(
+							callbackCommonFlags(),
+							&cli.StringFlag{
+								Name:  flagMessageType,
+								Usage: "消息类型: text, image, voice, video, location, link, event (默认 text，文本内容可作为参数给出)",
+							},
+							&cli.StringFlag{
+								Name:  flagCallbackEvent,
+								Usage: "事件类型 `EVENT`，如 enter_agent、click、change_contact，指定时消息类型为 event",
+							},
+							&cli.StringFlag{
+								Name:  flagCallbackFromUser,
+								Usage: "发送者 `USERID`",
+								Value: "workwxctl",
+							},
+							&cli.StringSliceFlag{
+								Name:  flagCallbackField,
+								Usage: "消息字段，格式为 `KEY=VALUE`，KEY 为 XML 元素名，如 EventKey=menu1 (可指定多次)",
+							},
+							&cli.StringFlag{
+								Name:  flagCallbackXMLFile,
+								Usage: "从 `FILE` 读取明文 XML 消息体，指定时忽略其他消息参数",
+							},
+							&cli.StringFlag{
+								Name:  flagCallbackReplay,
+								Usage: "依次重放 callback serve 录制的 `FILE` 中的全部回调，指定时忽略其他消息参数",
+							},
+						),
+					},
+					{
+						Name:   "echo",
+						Usage:  "模拟回调 URL 验证请求 (GET)",
+						Action: cmdCallbackEcho,
+						Flags:  callbackCommonFlags(),
+					},
+					{
+						Name:   "serve",
+						Usage:  "启动回调服务，打印收到的每条回调消息 (含类型特有字段与原始 XML)，不作被动回复",
+						Action: cmdCallbackServe,
+						Flags: append(
+							callbackCredentialFlags(),
+							&cli.StringFlag{
+								Name:  flagCallbackAddr,
+								Usage: "监听地址 `ADDR`",
+								Value: "[::]:8000",
+							},
+							&cli.BoolFlag{
+								Name:  flagCallbackJSON,
+								Usage: "每条消息输出为一行 JSON，便于以 jq 等工具处理",
+							},
+							&cli.StringFlag{
+								Name:  flagCallbackRecord,
+								Usage: "将收到的回调消息追加录制到 JSONL 文件 `FILE`，可用 callback send --replay 重放",
+							},
+						),
+					},
+				},
+			},
 		},
 	}
 }
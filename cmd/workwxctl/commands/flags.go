@@ -38,8 +38,26 @@ const (
 	flagButtonText       = "button-text"
 	flagSourceContentURL = "source-content-url"
 	flagDigest           = "digest"
+	flagArticlesFile     = "articles-file"
+
+	flagMiniprogramAppID     = "miniprogram-appid"
+	flagMiniprogramPage      = "miniprogram-page"
+	flagEmphasisFirstItem    = "emphasis-first-item"
+	flagMiniprogramContentKV = "content-item"
 
 	flagMediaType = "media-type"
+
+	flagCallbackToken               = "token"
+	flagCallbackEncodingAESKey      = "encoding-aes-key"
+	flagCallbackEncodingAESKeyShort = "key"
+	flagCallbackEvent               = "event"
+	flagCallbackFromUser            = "from-user"
+	flagCallbackField               = "field"
+	flagCallbackXMLFile             = "xml-file"
+	flagCallbackReplay              = "replay"
+	flagCallbackAddr                = "addr"
+	flagCallbackJSON                = "json"
+	flagCallbackRecord              = "record"
 )
 
 type cliOptions struct {
@@ -25,3 +25,33 @@ func (c *WorkwxApp) ListDepts(id int64) ([]*DeptInfo, error) {
 
 	return resp.Department, nil
 }
+
+// CreateDept 创建部门，返回新部门的 ID。
+func (c *WorkwxApp) CreateDept(dept *DeptCreateRequest) (int64, error) {
+	resp, err := c.execDeptCreate(reqDeptCreate{
+		Dept: dept,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.ID, nil
+}
+
+// UpdateDept 更新部门。
+//
+// 只会修改 update 中非 nil 的字段；ID 必填。
+func (c *WorkwxApp) UpdateDept(update *DeptUpdateRequest) error {
+	_, err := c.execDeptUpdate(reqDeptUpdate{
+		Update: update,
+	})
+	return err
+}
+
+// DeleteDept 删除部门。不能删除根部门，以及含有子部门或成员的部门。
+func (c *WorkwxApp) DeleteDept(id int64) error {
+	_, err := c.execDeptDelete(reqDeptDelete{
+		ID: id,
+	})
+	return err
+}
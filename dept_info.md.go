@@ -13,3 +13,31 @@ type DeptInfo struct {
 	// Order 在父部门中的次序值。order值大的排序靠前。值范围是[0, 2^32)
 	Order uint32 `json:"order"`
 }
+
+// DeptCreateRequest 创建部门参数
+type DeptCreateRequest struct {
+	// Name 部门名称。同一个层级的部门名称不能重复。长度限制为1~32个字符
+	Name string `json:"name"`
+	// NameEn 英文名称。同一个层级的部门名称不能重复
+	NameEn string `json:"name_en,omitempty"`
+	// ParentID 父部门id，32位整型
+	ParentID int64 `json:"parentid"`
+	// Order 在父部门中的次序值。order值大的排序靠前
+	Order uint32 `json:"order,omitempty"`
+	// ID 部门id，32位整型，指定时必须大于1。若不填该参数，将自动生成id
+	ID int64 `json:"id,omitempty"`
+}
+
+// DeptUpdateRequest 更新部门参数
+type DeptUpdateRequest struct {
+	// ID 部门id
+	ID int64 `json:"id"`
+	// Name 部门名称，为 nil 时不修改
+	Name *string `json:"name,omitempty"`
+	// NameEn 英文名称，为 nil 时不修改
+	NameEn *string `json:"name_en,omitempty"`
+	// ParentID 父部门id，为 nil 时不修改
+	ParentID *int64 `json:"parentid,omitempty"`
+	// Order 在父部门中的次序值，为 nil 时不修改
+	Order *uint32 `json:"order,omitempty"`
+}
@@ -0,0 +1,210 @@
+package workwx
+
+import (
+	"sort"
+	"strings"
+)
+
+// DeptTree 内存中的组织架构树
+//
+// 由 NewDeptTree 根据部门列表构建，构建后只读，可在多个 goroutine 中并发使用。
+// 父部门不在列表中的部门（如超出应用可见范围）会被当作根节点；
+// 对于成环的父子关系，环上最后被处理的部门会被当作根节点。
+type DeptTree struct {
+	roots []*DeptNode
+	nodes map[int64]*DeptNode
+}
+
+// DeptNode 组织架构树中的部门节点
+type DeptNode struct {
+	DeptInfo
+
+	// Parent 父部门节点，根节点为 nil
+	Parent *DeptNode
+	// Children 子部门节点，按 Order 从大到小排列
+	Children []*DeptNode
+}
+
+// NewDeptTree 根据部门列表构建组织架构树
+func NewDeptTree(depts []*DeptInfo) *DeptTree {
+	t := &DeptTree{
+		nodes: make(map[int64]*DeptNode, len(depts)),
+	}
+
+	for _, d := range depts {
+		t.nodes[d.ID] = &DeptNode{DeptInfo: *d}
+	}
+
+	for _, d := range depts {
+		n := t.nodes[d.ID]
+		parent, ok := t.nodes[d.ParentID]
+		if !ok || parent.hasAncestor(n) {
+			t.roots = append(t.roots, n)
+			continue
+		}
+		n.Parent = parent
+		parent.Children = append(parent.Children, n)
+	}
+
+	sortDeptNodes(t.roots)
+	for _, n := range t.nodes {
+		sortDeptNodes(n.Children)
+	}
+
+	return t
+}
+
+func sortDeptNodes(x []*DeptNode) {
+	sort.SliceStable(x, func(i, j int) bool {
+		if x[i].Order != x[j].Order {
+			return x[i].Order > x[j].Order
+		}
+		return x[i].ID < x[j].ID
+	})
+}
+
+// GetDeptTree 获取全量组织架构并构建组织架构树
+func (c *WorkwxApp) GetDeptTree() (*DeptTree, error) {
+	depts, err := c.ListAllDepts()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDeptTree(depts), nil
+}
+
+// Roots 根节点，按 Order 从大到小排列
+func (t *DeptTree) Roots() []*DeptNode {
+	return t.roots
+}
+
+// Node 按部门 ID 查找节点，不存在时返回 nil
+func (t *DeptTree) Node(id int64) *DeptNode {
+	return t.nodes[id]
+}
+
+// Len 树中的部门数
+func (t *DeptTree) Len() int {
+	return len(t.nodes)
+}
+
+// Walk 按先序遍历整棵树，参见 DeptNode.Walk
+func (t *DeptTree) Walk(fn func(n *DeptNode) bool) {
+	for _, r := range t.roots {
+		r.Walk(fn)
+	}
+}
+
+// LCA 查找两个部门的最近公共祖先（可以是部门自身）
+//
+// 任一部门不存在或两者不在同一棵子树中时返回 nil。
+func (t *DeptTree) LCA(a, b int64) *DeptNode {
+	na, nb := t.nodes[a], t.nodes[b]
+	if na == nil || nb == nil {
+		return nil
+	}
+
+	da, db := na.Depth(), nb.Depth()
+	for ; da > db; da-- {
+		na = na.Parent
+	}
+	for ; db > da; db-- {
+		nb = nb.Parent
+	}
+	for na != nb {
+		na, nb = na.Parent, nb.Parent
+	}
+
+	return na
+}
+
+// Depth 节点深度，根节点为 0
+func (n *DeptNode) Depth() int {
+	depth := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+func (n *DeptNode) hasAncestor(x *DeptNode) bool {
+	for p := n; p != nil; p = p.Parent {
+		if p == x {
+			return true
+		}
+	}
+	return false
+}
+
+// Ancestors 从根节点到本节点的路径，包括本节点
+func (n *DeptNode) Ancestors() []*DeptNode {
+	result := make([]*DeptNode, n.Depth()+1)
+	i := len(result) - 1
+	for p := n; p != nil; p = p.Parent {
+		result[i] = p
+		i--
+	}
+	return result
+}
+
+// Path 从根节点到本节点的部门名称，以 "/" 连接，如 "Company/R&D/Backend"
+func (n *DeptNode) Path() string {
+	ancestors := n.Ancestors()
+	names := make([]string, len(ancestors))
+	for i, a := range ancestors {
+		names[i] = a.Name
+	}
+	return strings.Join(names, "/")
+}
+
+// Walk 按先序遍历以本节点为根的子树
+//
+// fn 返回 false 时不再遍历该节点的子部门，但不影响其兄弟部门的遍历。
+func (n *DeptNode) Walk(fn func(n *DeptNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// SubtreeIDs 以本节点为根的子树中所有部门的 ID，按先序排列
+func (n *DeptNode) SubtreeIDs() []int64 {
+	var result []int64
+	n.Walk(func(x *DeptNode) bool {
+		result = append(result, x.ID)
+		return true
+	})
+	return result
+}
+
+// ListUsersInDeptSubtree 获取以指定部门为根的子树中的全部成员详情
+//
+// 逐个部门调用 ListUsersByDeptID，同时属于多个部门的成员只返回一次，
+// 顺序为其首次出现的顺序。部门不在 tree 中时返回空结果。
+func (c *WorkwxApp) ListUsersInDeptSubtree(tree *DeptTree, deptID int64) ([]*UserInfo, error) {
+	root := tree.Node(deptID)
+	if root == nil {
+		return nil, nil
+	}
+
+	var result []*UserInfo
+	seen := make(map[string]struct{})
+	for _, id := range root.SubtreeIDs() {
+		users, err := c.ListUsersByDeptID(id, false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range users {
+			if _, ok := seen[u.UserID]; ok {
+				continue
+			}
+			seen[u.UserID] = struct{}{}
+			result = append(result, u)
+		}
+	}
+
+	return result, nil
+}
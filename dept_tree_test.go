@@ -0,0 +1,157 @@
+package workwx
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func testDeptInfos() []*DeptInfo {
+	return []*DeptInfo{
+		{ID: 1, Name: "Company", ParentID: 0, Order: 100},
+		{ID: 2, Name: "R&D", ParentID: 1, Order: 10},
+		{ID: 3, Name: "Sales", ParentID: 1, Order: 20},
+		{ID: 4, Name: "Backend", ParentID: 2, Order: 1},
+		{ID: 5, Name: "Frontend", ParentID: 2, Order: 2},
+		{ID: 6, Name: "Partners", ParentID: 99, Order: 0},
+	}
+}
+
+func TestDeptTree(t *testing.T) {
+	c.Convey("组织架构树", t, func() {
+		tree := NewDeptTree(testDeptInfos())
+		c.So(tree.Len(), c.ShouldEqual, 6)
+		c.So(tree.Node(42), c.ShouldBeNil)
+
+		c.Convey("父子关系与排序", func() {
+			roots := tree.Roots()
+			c.So(roots, c.ShouldHaveLength, 2)
+			c.So(roots[0].ID, c.ShouldEqual, 1)
+			// parent not visible
+			c.So(roots[1].ID, c.ShouldEqual, 6)
+			c.So(roots[1].Parent, c.ShouldBeNil)
+
+			company := tree.Node(1)
+			c.So(company.Children[0].Name, c.ShouldEqual, "Sales")
+			c.So(company.Children[1].Name, c.ShouldEqual, "R&D")
+			c.So(tree.Node(4).Parent, c.ShouldEqual, tree.Node(2))
+			c.So(tree.Node(4).Depth(), c.ShouldEqual, 2)
+		})
+
+		c.Convey("路径", func() {
+			c.So(tree.Node(4).Path(), c.ShouldEqual, "Company/R&D/Backend")
+			c.So(tree.Node(1).Path(), c.ShouldEqual, "Company")
+		})
+
+		c.Convey("子树遍历", func() {
+			c.So(tree.Node(1).SubtreeIDs(), c.ShouldResemble, []int64{1, 3, 2, 5, 4})
+
+			var visited []int64
+			tree.Walk(func(n *DeptNode) bool {
+				visited = append(visited, n.ID)
+				return n.ID != 2
+			})
+			c.So(visited, c.ShouldResemble, []int64{1, 3, 2, 6})
+		})
+
+		c.Convey("最近公共祖先", func() {
+			c.So(tree.LCA(4, 5).ID, c.ShouldEqual, 2)
+			c.So(tree.LCA(4, 3).ID, c.ShouldEqual, 1)
+			c.So(tree.LCA(4, 2).ID, c.ShouldEqual, 2)
+			c.So(tree.LCA(4, 4).ID, c.ShouldEqual, 4)
+			c.So(tree.LCA(4, 6), c.ShouldBeNil)
+			c.So(tree.LCA(4, 42), c.ShouldBeNil)
+		})
+
+		c.Convey("成环的父子关系", func() {
+			tree := NewDeptTree([]*DeptInfo{
+				{ID: 1, Name: "a", ParentID: 2},
+				{ID: 2, Name: "b", ParentID: 1},
+			})
+			c.So(tree.Roots(), c.ShouldHaveLength, 1)
+			c.So(tree.Node(1).Path(), c.ShouldEqual, "b/a")
+		})
+	})
+}
+
+func TestDeptAPIs(t *testing.T) {
+	c.Convey("部门管理", t, func() {
+		var lastPath string
+		var lastBody string
+		var lastID string
+		var listedDepts []string
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			lastPath = r.URL.Path
+			switch r.URL.Path {
+			case "/cgi-bin/gettoken":
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			case "/cgi-bin/department/create":
+				body, _ := ioutil.ReadAll(r.Body)
+				lastBody = string(body)
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"created","id":7}`))
+			case "/cgi-bin/department/update":
+				body, _ := ioutil.ReadAll(r.Body)
+				lastBody = string(body)
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"updated"}`))
+			case "/cgi-bin/department/delete":
+				lastID = r.URL.Query().Get("id")
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"deleted"}`))
+			case "/cgi-bin/department/list":
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","department":[{"id":1,"name":"Company","parentid":0,"order":100},{"id":2,"name":"R&D","parentid":1,"order":10},{"id":4,"name":"Backend","parentid":2,"order":1}]}`))
+			case "/cgi-bin/user/list":
+				dept := r.URL.Query().Get("department_id")
+				listedDepts = append(listedDepts, dept)
+				var body string
+				switch dept {
+				case "2":
+					body = `[{"userid":"lisi","name":"李四","department":[2,4],"order":[0,0],"is_leader_in_dept":[1,0],"gender":"2","status":1,"enable":1}]`
+				case "4":
+					body = `[{"userid":"lisi","name":"李四","department":[2,4],"order":[0,0],"is_leader_in_dept":[1,0],"gender":"2","status":1,"enable":1},{"userid":"wangwu","name":"王五","department":[4],"order":[0],"is_leader_in_dept":[0],"gender":"1","status":1,"enable":1}]`
+				default:
+					body = `[]`
+				}
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","userlist":` + body + `}`))
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		app := New("ww6a112864f8022910", WithQYAPIHost(server.URL)).WithApp("secret", 1000001)
+
+		c.Convey("创建、更新、删除部门", func() {
+			id, err := app.CreateDept(&DeptCreateRequest{Name: "Backend", ParentID: 2})
+			c.So(err, c.ShouldBeNil)
+			c.So(id, c.ShouldEqual, 7)
+			c.So(lastBody, c.ShouldEqual, `{"name":"Backend","parentid":2}`)
+
+			name := "Server"
+			c.So(app.UpdateDept(&DeptUpdateRequest{ID: 7, Name: &name}), c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/department/update")
+			c.So(lastBody, c.ShouldEqual, `{"id":7,"name":"Server"}`)
+
+			c.So(app.DeleteDept(7), c.ShouldBeNil)
+			c.So(lastID, c.ShouldEqual, "7")
+		})
+
+		c.Convey("获取子树中的全部成员", func() {
+			tree, err := app.GetDeptTree()
+			c.So(err, c.ShouldBeNil)
+			c.So(tree.Node(4).Path(), c.ShouldEqual, "Company/R&D/Backend")
+
+			users, err := app.ListUsersInDeptSubtree(tree, 2)
+			c.So(err, c.ShouldBeNil)
+			c.So(listedDepts, c.ShouldResemble, []string{"2", "4"})
+			c.So(users, c.ShouldHaveLength, 2)
+			c.So(users[0].UserID, c.ShouldEqual, "lisi")
+			c.So(users[1].UserID, c.ShouldEqual, "wangwu")
+
+			users, err = app.ListUsersInDeptSubtree(tree, 42)
+			c.So(err, c.ShouldBeNil)
+			c.So(users, c.ShouldBeEmpty)
+		})
+	})
+}
@@ -3,11 +3,11 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
 
-	"github.com/gin-gonic/gin"
 	"github.com/xen0n/go-workwx"
 )
 
@@ -16,14 +16,16 @@ type dummyRxMessageHandler struct{}
 var _ workwx.RxMessageHandler = &dummyRxMessageHandler{}
 
 // OnIncomingMessage 一条消息到来时的回调。
-func (o *dummyRxMessageHandler) OnIncomingMessage(ctx *gin.Context, msg *workwx.RxMessage) error {
+func (o *dummyRxMessageHandler) OnIncomingMessage(ctx context.Context, msg *workwx.RxMessage) (workwx.RxReply, error) {
 	// You can do much more!
 	fmt.Printf("incoming message: %s\n", msg)
 
-	if ctx != nil {
-		ctx.Data(http.StatusOK, gin.MIMEJSON, []byte{""})
+	// 被动回复：原样复读文本消息
+	if text, ok := msg.Text(); ok {
+		return workwx.TextReply{Content: text.GetContent()}, nil
 	}
-	return nil
+
+	return nil, nil
 }
 
 func main() {
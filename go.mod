@@ -8,9 +8,9 @@ require (
 	github.com/antchfx/htmlquery v1.2.4 // indirect
 	github.com/antchfx/xmlquery v1.3.7 // indirect
 	github.com/cenkalti/backoff/v4 v4.0.0
-	github.com/gin-gonic/gin v1.7.4
 	github.com/gobwas/glob v0.2.3 // indirect
 	github.com/gocolly/colly v1.2.0
+	github.com/golang/protobuf v1.3.3 // indirect
 	github.com/kennygrant/sanitize v1.2.4 // indirect
 	github.com/russross/blackfriday/v2 v2.0.1
 	github.com/saintfish/chardet v0.0.0-20120816061221-3af4cd4741ca // indirect
@@ -19,6 +19,7 @@ require (
 	github.com/temoto/robotstxt v1.1.2 // indirect
 	github.com/urfave/cli/v2 v2.2.0
 	golang.org/x/net v0.0.0-20200813134508-3edf25e44fcc
+	gopkg.in/yaml.v2 v2.2.8 // indirect
 )
 
 replace github.com/sirupsen/logrus v1.8.1 => github.com/vinerr/logrus v1.9.8-0.20210929085848-e27d033b088c
@@ -2,6 +2,7 @@ package envelope
 
 import (
 	"io"
+	"time"
 )
 
 type ProcessorOption interface {
@@ -31,3 +32,47 @@ func WithTimeSource(t TimeSource) ProcessorOption {
 func (o *customTimeSource) applyTo(x *Processor) {
 	x.timeSource = o.inner
 }
+
+type customTimestampWindow struct {
+	inner time.Duration
+}
+
+// WithTimestampWindow rejects incoming messages whose timestamp deviates from
+// the current time by more than d. A zero d disables the check (the default).
+func WithTimestampWindow(d time.Duration) ProcessorOption {
+	return &customTimestampWindow{inner: d}
+}
+
+func (o *customTimestampWindow) applyTo(x *Processor) {
+	x.timestampWindow = o.inner
+}
+
+type customNonceCache struct {
+	inner NonceCache
+}
+
+// WithNonceCache rejects incoming messages whose nonce has been seen before,
+// as recorded in the given NonceCache. A nil cache disables the check (the
+// default).
+func WithNonceCache(c NonceCache) ProcessorOption {
+	return &customNonceCache{inner: c}
+}
+
+func (o *customNonceCache) applyTo(x *Processor) {
+	x.nonceCache = o.inner
+}
+
+type customReceiveIDs struct {
+	inner []string
+}
+
+// WithReceiveIDs rejects incoming messages whose decrypted ReceiveID is not
+// one of ids. For apps the ReceiveID is the CorpID; for suite callbacks it is
+// the SuiteID. Passing no ids disables the check (the default).
+func WithReceiveIDs(ids ...string) ProcessorOption {
+	return &customReceiveIDs{inner: ids}
+}
+
+func (o *customReceiveIDs) applyTo(x *Processor) {
+	x.receiveIDs = o.inner
+}
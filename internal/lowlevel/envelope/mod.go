@@ -4,20 +4,25 @@ import (
 	"crypto/rand"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/xen0n/go-workwx/internal/lowlevel/encryptor"
 	"github.com/xen0n/go-workwx/internal/lowlevel/signature"
 )
 
 type Processor struct {
-	token         string
-	encryptor     *encryptor.WorkwxEncryptor
-	entropySource io.Reader
-	timeSource    TimeSource
+	token           string
+	encryptor       *encryptor.WorkwxEncryptor
+	entropySource   io.Reader
+	timeSource      TimeSource
+	timestampWindow time.Duration
+	nonceCache      NonceCache
+	receiveIDs      []string
 }
 
 func NewProcessor(
@@ -47,7 +52,42 @@ func NewProcessor(
 	return &obj, nil
 }
 
-var errInvalidSignature = errors.New("invalid signature")
+// ErrInvalidSignature is returned when an incoming message's signature does
+// not match the configured token.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ErrMalformedEchoStr is returned when an echo test request carries no
+// echostr or more than one.
+var ErrMalformedEchoStr = errors.New("malformed echostr")
+
+// ErrStaleTimestamp is returned when an incoming message's timestamp falls
+// outside the configured freshness window.
+var ErrStaleTimestamp = errors.New("stale or malformed timestamp")
+
+// ErrDuplicateNonce is returned when an incoming message's nonce has been
+// seen before, i.e. the request is likely a replay.
+var ErrDuplicateNonce = errors.New("duplicate nonce")
+
+// ReceiveIDMismatchError is returned when a decrypted message's ReceiveID is
+// not among the ones configured with WithReceiveIDs.
+type ReceiveIDMismatchError struct {
+	Expected []string
+	Actual   string
+}
+
+var _ error = (*ReceiveIDMismatchError)(nil)
+
+func (e *ReceiveIDMismatchError) Error() string {
+	return fmt.Sprintf(
+		"unexpected ReceiveID %q, expecting one of %q",
+		e.Actual,
+		e.Expected,
+	)
+}
+
+// defaultNonceTTL is how long nonces are remembered when no timestamp window
+// is configured.
+const defaultNonceTTL = 10 * time.Minute
 
 func (p *Processor) HandleIncomingMsg(url *url.URL, body []byte) (Envelope, error) {
 	// xml unmarshal
@@ -59,12 +99,25 @@ func (p *Processor) HandleIncomingMsg(url *url.URL, body []byte) (Envelope, erro
 
 	// check signature
 	if !signature.VerifyHTTPRequestSignature(p.token, url, x.Encrypt) {
-		return Envelope{}, errInvalidSignature
+		return Envelope{}, ErrInvalidSignature
+	}
+
+	// check for replays, only after the request is proven authentic
+	err = p.checkReplay(url)
+	if err != nil {
+		return Envelope{}, err
 	}
 
 	// decrypt message
 	msg, err := p.encryptor.Decrypt([]byte(x.Encrypt))
 	if err != nil {
+		_ = p.ReleaseNonce(url)
+		return Envelope{}, err
+	}
+
+	err = p.CheckReceiveID(msg.ReceiveID)
+	if err != nil {
+		_ = p.ReleaseNonce(url)
 		return Envelope{}, err
 	}
 
@@ -77,22 +130,146 @@ func (p *Processor) HandleIncomingMsg(url *url.URL, body []byte) (Envelope, erro
 	}, nil
 }
 
-func (p *Processor) MakeOutgoingEnvelope(msg []byte) ([]byte, error) {
-	workwxPayload := encryptor.WorkwxPayload{
-		Msg:       msg,
-		ReceiveID: nil,
+// CheckReceiveID checks the given decrypted ReceiveID against the ones
+// configured with WithReceiveIDs, returning a *ReceiveIDMismatchError on
+// mismatch. It always succeeds if no ReceiveIDs are configured.
+func (p *Processor) CheckReceiveID(receiveID []byte) error {
+	if len(p.receiveIDs) == 0 {
+		return nil
 	}
-	encryptedMsg, err := p.encryptor.Encrypt(&workwxPayload)
+
+	for _, id := range p.receiveIDs {
+		if string(receiveID) == id {
+			return nil
+		}
+	}
+
+	return &ReceiveIDMismatchError{
+		Expected: p.receiveIDs,
+		Actual:   string(receiveID),
+	}
+}
+
+// HandleEchoTest verifies and decrypts the echostr of an echo test request,
+// i.e. the GET request WeCom sends when the callback URL is configured. The
+// decrypted echostr is to be sent back verbatim as the response body.
+func (p *Processor) HandleEchoTest(url *url.URL) ([]byte, error) {
+	if !signature.VerifyHTTPRequestSignature(p.token, url, "") {
+		return nil, ErrInvalidSignature
+	}
+
+	l := url.Query()["echostr"]
+	if len(l) != 1 {
+		return nil, ErrMalformedEchoStr
+	}
+
+	payload, err := p.encryptor.Decrypt([]byte(l[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.CheckReceiveID(payload.ReceiveID)
 	if err != nil {
 		return nil, err
 	}
 
+	return payload.Msg, nil
+}
+
+func (p *Processor) checkReplay(url *url.URL) error {
+	if p.timestampWindow <= 0 && p.nonceCache == nil {
+		return nil
+	}
+
+	query := url.Query()
+	tsStr := query.Get("timestamp")
+
+	if p.timestampWindow > 0 {
+		ts, err := strconv.ParseInt(tsStr, 10, 64)
+		if err != nil {
+			return ErrStaleTimestamp
+		}
+
+		delta := p.timeSource.GetCurrentTimestamp().Sub(time.Unix(ts, 0))
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta > p.timestampWindow {
+			return ErrStaleTimestamp
+		}
+	}
+
+	if p.nonceCache != nil {
+		ttl := defaultNonceTTL
+		if p.timestampWindow > 0 {
+			// requests older than this are rejected anyway
+			ttl = 2 * p.timestampWindow
+		}
+
+		fresh, err := p.nonceCache.MarkIfAbsent(nonceKey(query), ttl)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrDuplicateNonce
+		}
+	}
+
+	return nil
+}
+
+// ReleaseNonce forgets the nonce of the request with the given URL, which
+// HandleIncomingMsg has recorded for replay protection.
+//
+// It is to be called when the request could not be handled after all, so
+// that WeCom's retry of the same request (bearing the same timestamp and
+// nonce) is accepted instead of being rejected with ErrDuplicateNonce.
+func (p *Processor) ReleaseNonce(url *url.URL) error {
+	if p.nonceCache == nil {
+		return nil
+	}
+
+	return p.nonceCache.Forget(nonceKey(url.Query()))
+}
+
+func nonceKey(query url.Values) string {
+	return query.Get("timestamp") + ":" + query.Get("nonce")
+}
+
+func (p *Processor) MakeOutgoingEnvelope(msg []byte) ([]byte, error) {
+	return p.MakeOutgoingEnvelopeWithReceiveID(msg, nil)
+}
+
+// MakeOutgoingEnvelopeWithReceiveID makes an outgoing envelope with the
+// given ReceiveID embedded in the encrypted payload, as is required for
+// passive replies (where the ReceiveID should be the CorpID).
+func (p *Processor) MakeOutgoingEnvelopeWithReceiveID(msg []byte, receiveID []byte) ([]byte, error) {
 	ts := p.timeSource.GetCurrentTimestamp().Unix()
 	nonce, err := makeNonce(p.entropySource)
 	if err != nil {
 		return nil, err
 	}
 
+	return p.MakeOutgoingEnvelopeAt(msg, receiveID, ts, nonce)
+}
+
+// MakeOutgoingEnvelopeAt is like MakeOutgoingEnvelopeWithReceiveID, but with
+// the timestamp and nonce given by the caller instead of generated.
+func (p *Processor) MakeOutgoingEnvelopeAt(
+	msg []byte,
+	receiveID []byte,
+	ts int64,
+	nonce string,
+) ([]byte, error) {
+	workwxPayload := encryptor.WorkwxPayload{
+		Msg:       msg,
+		ReceiveID: receiveID,
+	}
+	encryptedMsg, err := p.encryptor.Encrypt(&workwxPayload)
+	if err != nil {
+		return nil, err
+	}
+
 	msgSignature := signature.MakeDevMsgSignature(
 		p.token,
 		strconv.FormatInt(ts, 10),
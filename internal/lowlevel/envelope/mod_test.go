@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"testing"
+	"time"
 
 	c "github.com/smartystreets/goconvey/convey"
 )
@@ -71,3 +72,143 @@ func TestProcessor(t *testing.T) {
 		c.So(rtt.ReceiveID, c.ShouldBeEmpty)
 	})
 }
+
+type fixedTimeSource struct {
+	t time.Time
+}
+
+func (x fixedTimeSource) GetCurrentTimestamp() time.Time {
+	return x.t
+}
+
+func TestProcessorReplayProtection(t *testing.T) {
+	//nolint: gosec  // randomly generated for test purposes only
+	token := "kz7Yx62CH8SaLN"
+	encodingAESKey := "cD0d7jx4tYvVtzqrmh3Dm3QFCXe6f8SlHoMtMh3qQEP"
+	s := "http://test.example.com/?msg_signature=f265ae551b1932727204c3d707628d01376a6940&timestamp=1583995625&nonce=1584392382"
+	body := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><Encrypt><![CDATA[EUCt7xMcNiyASzZj0Hjc5yDjFQrCum6AfQ3ntHiUzjGQ51xieKmbvtrZ40/EcB2W/W8yH0n4Lqx48gJl/T9HD/R309I0P/r5pIZucK3lyEn48FYMr4YdE0QdL2jIJ3xkcXUr6uzefzCxG6lMvwpAJaOyVCzN7sRRw47njfxy5EIqU6R9ZBhlTzfdnhhOhK/nTwzrZX3SoGlXFA9OBeZ6ru1NWpXFk76x9DUMe0lcxPPiUqK8ctnQcYXSGUHVqC6DfG7E7mab0OmruNN8cBZY5d3dYOBA4OgaH55Q0AJmUpdT8vNiXpXx+6TxT3TIjySXpDrHVyrsb772aYywgg/Nu4kUmGkALwFZlzhjNegR7wDwb9lr4ERXsSSS8JZ8lbBmaQ3F2Tq584xoPj5rIhXAF734ynm4no1g+SdHiNqR328=]]></Encrypt><AgentID><![CDATA[1000002]]></AgentID></xml>")
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	msgTime := time.Unix(1583995625, 0)
+
+	c.Convey("时间戳超出窗口的请求应该被拒绝", t, func() {
+		pr, err := NewProcessor(
+			token,
+			encodingAESKey,
+			WithTimeSource(fixedTimeSource{msgTime.Add(10 * time.Minute)}),
+			WithTimestampWindow(5*time.Minute),
+		)
+		c.So(err, c.ShouldBeNil)
+
+		_, err = pr.HandleIncomingMsg(u, body)
+		c.So(err, c.ShouldEqual, ErrStaleTimestamp)
+	})
+
+	c.Convey("时间戳在窗口内的请求应该被接受", t, func() {
+		pr, err := NewProcessor(
+			token,
+			encodingAESKey,
+			WithTimeSource(fixedTimeSource{msgTime.Add(-time.Minute)}),
+			WithTimestampWindow(5*time.Minute),
+		)
+		c.So(err, c.ShouldBeNil)
+
+		_, err = pr.HandleIncomingMsg(u, body)
+		c.So(err, c.ShouldBeNil)
+	})
+
+	c.Convey("重放的请求应该被拒绝", t, func() {
+		cache := NewMemoryNonceCache(16)
+		pr, err := NewProcessor(token, encodingAESKey, WithNonceCache(cache))
+		c.So(err, c.ShouldBeNil)
+
+		_, err = pr.HandleIncomingMsg(u, body)
+		c.So(err, c.ShouldBeNil)
+
+		_, err = pr.HandleIncomingMsg(u, body)
+		c.So(err, c.ShouldEqual, ErrDuplicateNonce)
+
+		c.Convey("共享同一 NonceCache 的 Processor 也应该拒绝", func() {
+			pr2, err := NewProcessor(token, encodingAESKey, WithNonceCache(cache))
+			c.So(err, c.ShouldBeNil)
+
+			_, err = pr2.HandleIncomingMsg(u, body)
+			c.So(err, c.ShouldEqual, ErrDuplicateNonce)
+		})
+
+		c.Convey("释放 nonce 后的重试应该被接受", func() {
+			c.So(pr.ReleaseNonce(u), c.ShouldBeNil)
+
+			_, err = pr.HandleIncomingMsg(u, body)
+			c.So(err, c.ShouldBeNil)
+		})
+	})
+}
+
+func TestProcessorReceiveID(t *testing.T) {
+	//nolint: gosec  // randomly generated for test purposes only
+	token := "kz7Yx62CH8SaLN"
+	encodingAESKey := "cD0d7jx4tYvVtzqrmh3Dm3QFCXe6f8SlHoMtMh3qQEP"
+	s := "http://test.example.com/?msg_signature=f265ae551b1932727204c3d707628d01376a6940&timestamp=1583995625&nonce=1584392382"
+	body := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><Encrypt><![CDATA[EUCt7xMcNiyASzZj0Hjc5yDjFQrCum6AfQ3ntHiUzjGQ51xieKmbvtrZ40/EcB2W/W8yH0n4Lqx48gJl/T9HD/R309I0P/r5pIZucK3lyEn48FYMr4YdE0QdL2jIJ3xkcXUr6uzefzCxG6lMvwpAJaOyVCzN7sRRw47njfxy5EIqU6R9ZBhlTzfdnhhOhK/nTwzrZX3SoGlXFA9OBeZ6ru1NWpXFk76x9DUMe0lcxPPiUqK8ctnQcYXSGUHVqC6DfG7E7mab0OmruNN8cBZY5d3dYOBA4OgaH55Q0AJmUpdT8vNiXpXx+6TxT3TIjySXpDrHVyrsb772aYywgg/Nu4kUmGkALwFZlzhjNegR7wDwb9lr4ERXsSSS8JZ8lbBmaQ3F2Tq584xoPj5rIhXAF734ynm4no1g+SdHiNqR328=]]></Encrypt><AgentID><![CDATA[1000002]]></AgentID></xml>")
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	c.Convey("ReceiveID 匹配时应该被接受", t, func() {
+		pr, err := NewProcessor(
+			token,
+			encodingAESKey,
+			WithReceiveIDs("wwsomeothercorp", "ww6a112864f8022910"),
+		)
+		c.So(err, c.ShouldBeNil)
+
+		_, err = pr.HandleIncomingMsg(u, body)
+		c.So(err, c.ShouldBeNil)
+	})
+
+	c.Convey("ReceiveID 不匹配时应该被拒绝", t, func() {
+		pr, err := NewProcessor(token, encodingAESKey, WithReceiveIDs("wwsomeothercorp"))
+		c.So(err, c.ShouldBeNil)
+
+		_, err = pr.HandleIncomingMsg(u, body)
+		c.So(err, c.ShouldNotBeNil)
+
+		mismatch, ok := err.(*ReceiveIDMismatchError)
+		c.So(ok, c.ShouldBeTrue)
+		c.So(mismatch.Actual, c.ShouldEqual, "ww6a112864f8022910")
+		c.So(mismatch.Expected, c.ShouldResemble, []string{"wwsomeothercorp"})
+	})
+}
+
+func TestMemoryNonceCache(t *testing.T) {
+	c.Convey("MemoryNonceCache", t, func() {
+		now := time.Unix(1583995625, 0)
+		cache := NewMemoryNonceCache(2)
+		cache.now = func() time.Time { return now }
+
+		mark := func(key string) bool {
+			ok, err := cache.MarkIfAbsent(key, time.Minute)
+			c.So(err, c.ShouldBeNil)
+			return ok
+		}
+
+		c.So(mark("a"), c.ShouldBeTrue)
+		c.So(mark("a"), c.ShouldBeFalse)
+
+		c.Convey("过期后应该可以再次记录", func() {
+			now = now.Add(2 * time.Minute)
+			c.So(mark("a"), c.ShouldBeTrue)
+		})
+
+		c.Convey("超出容量时应该淘汰最早的记录", func() {
+			c.So(mark("b"), c.ShouldBeTrue)
+			c.So(mark("c"), c.ShouldBeTrue)
+			c.So(mark("a"), c.ShouldBeTrue)
+			c.So(mark("c"), c.ShouldBeFalse)
+		})
+	})
+}
@@ -10,6 +10,32 @@ type xmlRxEnvelope struct {
 	Encrypt    string `xml:"Encrypt"`
 }
 
+// Header is the plaintext part of an incoming envelope, available before
+// signature verification and decryption.
+type Header struct {
+	ToUserName string
+	AgentID    string
+	Encrypt    string
+}
+
+// PeekHeader parses the plaintext part of an incoming envelope without
+// verifying or decrypting it, e.g. for picking the right credentials when
+// serving multiple apps on one endpoint. The result must not be trusted
+// until the envelope is verified.
+func PeekHeader(body []byte) (Header, error) {
+	var x xmlRxEnvelope
+	err := xml.Unmarshal(body, &x)
+	if err != nil {
+		return Header{}, err
+	}
+
+	return Header{
+		ToUserName: x.ToUserName,
+		AgentID:    x.AgentID,
+		Encrypt:    x.Encrypt,
+	}, nil
+}
+
 type cdataNode struct {
 	CData string `xml:",cdata"`
 }
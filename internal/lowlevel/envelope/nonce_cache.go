@@ -0,0 +1,106 @@
+package envelope
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceCache remembers recently seen callback nonces for replay protection.
+//
+// Implementations may be shared between multiple Processors (and even
+// multiple processes, if backed by an external store).
+type NonceCache interface {
+	// MarkIfAbsent records the nonce key for ttl, and reports whether the
+	// key was absent (i.e. not seen within its TTL) before the call.
+	MarkIfAbsent(key string, ttl time.Duration) (bool, error)
+	// Forget removes the nonce key, so that a retry of a request that failed
+	// to be handled is not rejected as a replay.
+	Forget(key string) error
+}
+
+// MemoryNonceCache is a bounded in-memory NonceCache.
+//
+// When full, the oldest entries are evicted first, regardless of whether
+// they have expired.
+type MemoryNonceCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]memoryNonceCacheEntry
+	// order holds keys in insertion order, as a ring buffer
+	order []string
+	head  int
+	now   func() time.Time
+}
+
+type memoryNonceCacheEntry struct {
+	expiry time.Time
+	// slot is the entry's index in the ring buffer
+	slot int
+}
+
+var _ NonceCache = (*MemoryNonceCache)(nil)
+
+// NewMemoryNonceCache makes a MemoryNonceCache holding at most capacity
+// entries.
+func NewMemoryNonceCache(capacity int) *MemoryNonceCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return &MemoryNonceCache{
+		capacity: capacity,
+		entries:  make(map[string]memoryNonceCacheEntry, capacity),
+		order:    make([]string, 0, capacity),
+		now:      time.Now,
+	}
+}
+
+// MarkIfAbsent implements NonceCache.
+func (c *MemoryNonceCache) MarkIfAbsent(key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if e, ok := c.entries[key]; ok {
+		if now.Before(e.expiry) {
+			return false, nil
+		}
+
+		// expired: refresh in place, keeping its slot in the ring
+		e.expiry = now.Add(ttl)
+		c.entries[key] = e
+		return true, nil
+	}
+
+	var slot int
+	if len(c.order) < c.capacity {
+		slot = len(c.order)
+		c.order = append(c.order, key)
+	} else {
+		// evict the oldest entry, unless it has been forgotten and re-added
+		// to another slot since
+		slot = c.head
+		oldKey := c.order[slot]
+		if e, ok := c.entries[oldKey]; ok && e.slot == slot {
+			delete(c.entries, oldKey)
+		}
+		c.order[slot] = key
+		c.head = (c.head + 1) % c.capacity
+	}
+	c.entries[key] = memoryNonceCacheEntry{
+		expiry: now.Add(ttl),
+		slot:   slot,
+	}
+
+	return true, nil
+}
+
+// Forget removes the key, so that a subsequent MarkIfAbsent with the same key
+// reports it as absent.
+func (c *MemoryNonceCache) Forget(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
@@ -5,8 +5,6 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-
-	"github.com/xen0n/go-workwx/internal/lowlevel/signature"
 )
 
 type ToEchoTestAPIArgs interface {
@@ -80,19 +78,14 @@ func (x URLValuesForEchoTestAPI) ToEchoTestAPIArgs() (EchoTestAPIArgs, error) {
 
 func (h *LowLevelHandler) echoTestHandler(rw http.ResponseWriter, r *http.Request) {
 	url := r.URL
-	if !signature.VerifyHTTPRequestSignature(h.token, url, "") {
-		rw.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
 	adapter := URLValuesForEchoTestAPI(url.Query())
-	args, err := adapter.ToEchoTestAPIArgs()
+	_, err := adapter.ToEchoTestAPIArgs()
 	if err != nil {
 		rw.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	payload, err := h.encryptor.Decrypt([]byte(args.EchoStr))
+	msg, err := h.ep.HandleEchoTest(url)
 	if err != nil {
 		rw.WriteHeader(http.StatusBadRequest)
 		return
@@ -100,5 +93,5 @@ func (h *LowLevelHandler) echoTestHandler(rw http.ResponseWriter, r *http.Reques
 
 	rw.WriteHeader(http.StatusOK)
 	// No way to signal failure with the typical HTTP handler method signature
-	_, _ = rw.Write(payload.Msg)
+	_, _ = rw.Write(msg)
 }
@@ -1,16 +1,46 @@
 package httpapi
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 
-	"github.com/gin-gonic/gin"
-
 	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
 )
 
+// StatusCoder is implemented by errors that want a specific HTTP status code
+// when returned from EnvelopeHandler; other errors result in a 500.
+type StatusCoder interface {
+	HTTPStatusCode() int
+}
+
+func statusCodeForError(err error) int {
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		return sc.HTTPStatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// statusCodeForEnvelopeError maps errors from HandleIncomingMsg to HTTP
+// status codes, so that replays can be told apart from malformed requests.
+func statusCodeForEnvelopeError(err error) int {
+	switch {
+	case errors.Is(err, envelope.ErrStaleTimestamp):
+		return http.StatusForbidden
+	case errors.Is(err, envelope.ErrDuplicateNonce):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 type EnvelopeHandler interface {
-	OnIncomingEnvelope(ctx *gin.Context, rx envelope.Envelope) error
+	// OnIncomingEnvelope handles an incoming envelope, optionally returning
+	// a plaintext XML reply to be sent back passively. A nil reply means
+	// nothing is to be replied.
+	OnIncomingEnvelope(ctx context.Context, rx envelope.Envelope) ([]byte, error)
 }
 
 func (h *LowLevelHandler) eventHandler(rw http.ResponseWriter, r *http.Request) {
@@ -26,20 +56,35 @@ func (h *LowLevelHandler) eventHandler(rw http.ResponseWriter, r *http.Request)
 	// signature verification is inside EnvelopeProcessor
 	ev, err := h.ep.HandleIncomingMsg(r.URL, body)
 	if err != nil {
-		rw.WriteHeader(http.StatusBadRequest)
+		rw.WriteHeader(statusCodeForEnvelopeError(err))
 		return
 	}
 
-	err = h.eh.OnIncomingEnvelope(h.ctx, ev)
+	reply, err := h.eh.OnIncomingEnvelope(r.Context(), ev)
 	if err != nil {
-		rw.WriteHeader(http.StatusInternalServerError)
+		// let WeCom's retry through
+		_ = h.ep.ReleaseNonce(r.URL)
+		rw.WriteHeader(statusCodeForError(err))
 		return
 	}
 
-	// currently we always return empty 200 responses
-	// any reply is to be sent asynchronously
-	// this might change in the future (maybe save a couple of RTT or so)
-	if h.ctx == nil {
+	if reply == nil {
+		// nothing to reply passively, respond with an empty 200
+		// any reply is to be sent asynchronously
 		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// passive replies are encrypted with the CorpID as ReceiveID
+	out, err := h.ep.MakeOutgoingEnvelopeWithReceiveID(reply, ev.ReceiveID)
+	if err != nil {
+		_ = h.ep.ReleaseNonce(r.URL)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+
+	rw.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	// No way to signal failure with the typical HTTP handler method signature
+	_, _ = rw.Write(out)
 }
@@ -2,19 +2,16 @@ package httpapi
 
 import (
 	"net/http"
+	"net/url"
 
-	"github.com/gin-gonic/gin"
-
-	"github.com/xen0n/go-workwx/internal/lowlevel/encryptor"
 	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
+	"github.com/xen0n/go-workwx/internal/lowlevel/signature"
 )
 
 type LowLevelHandler struct {
-	token     string
-	encryptor *encryptor.WorkwxEncryptor
-	ep        *envelope.Processor
-	eh        EnvelopeHandler
-	ctx       *gin.Context
+	token string
+	ep    *envelope.Processor
+	eh    EnvelopeHandler
 }
 
 var _ http.Handler = (*LowLevelHandler)(nil)
@@ -23,29 +20,20 @@ func NewLowLevelHandler(
 	token string,
 	encodingAESKey string,
 	eh EnvelopeHandler,
+	opts ...envelope.ProcessorOption,
 ) (*LowLevelHandler, error) {
-	enc, err := encryptor.NewWorkwxEncryptor(encodingAESKey)
-	if err != nil {
-		return nil, err
-	}
-
-	ep, err := envelope.NewProcessor(token, encodingAESKey)
+	ep, err := envelope.NewProcessor(token, encodingAESKey, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &LowLevelHandler{
-		token:     token,
-		encryptor: enc,
-		ep:        ep,
-		eh:        eh,
+		token: token,
+		ep:    ep,
+		eh:    eh,
 	}, nil
 }
 
-func (h *LowLevelHandler) SetGinContext(c *gin.Context) {
-	h.ctx = c
-}
-
 func (h *LowLevelHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -61,3 +49,10 @@ func (h *LowLevelHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		rw.WriteHeader(http.StatusNotImplemented)
 	}
 }
+
+// VerifySignature reports whether the request with the given URL is signed
+// with this handler's token. encrypt is the Encrypt field of the envelope for
+// event requests, and empty for echo test requests.
+func (h *LowLevelHandler) VerifySignature(u *url.URL, encrypt string) bool {
+	return signature.VerifyHTTPRequestSignature(h.token, u, encrypt)
+}
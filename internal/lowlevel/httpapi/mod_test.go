@@ -1,20 +1,48 @@
 package httpapi
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
 )
 
+type replyingEnvelopeHandler struct{}
+
+type unavailableError struct{}
+
+func (unavailableError) Error() string       { return "unavailable" }
+func (unavailableError) HTTPStatusCode() int { return http.StatusServiceUnavailable }
+
+func (h *replyingEnvelopeHandler) OnIncomingEnvelope(ctx context.Context, rx envelope.Envelope) ([]byte, error) {
+	switch string(rx.Msg) {
+	case "noreply":
+		return nil, nil
+	case "fail":
+		return nil, errors.New("fail")
+	case "busy":
+		return nil, fmt.Errorf("wrapped: %w", unavailableError{})
+	}
+	return []byte("reply to " + string(rx.Msg)), nil
+}
+
 func TestLowlevelHandler(t *testing.T) {
 	c.Convey("E2E HTTP handler tests", t, func() {
 		//nolint: gosec  // randomly generated for test purposes only
 		token := "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme"
 		encodingAESKey := "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws"
-		handler, err := NewLowlevelHandler(token, encodingAESKey, nil)
+		handler, err := NewLowLevelHandler(token, encodingAESKey, &replyingEnvelopeHandler{})
 		c.So(err, c.ShouldBeNil)
 		c.So(handler, c.ShouldNotBeNil)
 
@@ -33,5 +61,167 @@ func TestLowlevelHandler(t *testing.T) {
 			c.So(err, c.ShouldBeNil)
 			c.So(body, c.ShouldResemble, []byte("94966531020182955848408"))
 		})
+
+		c.Convey("ReceiveID 不符的回调模式请求应该被拒绝", func() {
+			h, err := NewLowLevelHandler(
+				token,
+				encodingAESKey,
+				&replyingEnvelopeHandler{},
+				envelope.WithReceiveIDs("wwsomeothercorp"),
+			)
+			c.So(err, c.ShouldBeNil)
+
+			req := httptest.NewRequest(http.MethodGet, "/test?echostr=6KmUQuPVu7UhjyVqRdbo5SfcRqaHvbUlKSHFvBV2ZuR6TIlKsygcfeSd1GDplg1C5KSKr6UPHCaC%2FnIX3ZNt9w%3D%3D&msg_signature=1ba3cb09c0d2c2b3ed6900d37f91a6efae6cb011&timestamp=1583940690&nonce=VHh7ymSeb0jc4lSb", nil)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			c.So(rec.Code, c.ShouldEqual, http.StatusBadRequest)
+		})
+
+		c.Convey("回调事件请求", func() {
+			pr, err := envelope.NewProcessor(token, encodingAESKey)
+			c.So(err, c.ShouldBeNil)
+
+			post := func(msg string) *http.Response {
+				reqBody, err := pr.MakeOutgoingEnvelopeWithReceiveID([]byte(msg), []byte("corpid"))
+				c.So(err, c.ShouldBeNil)
+
+				var x struct {
+					MsgSignature string `xml:"MsgSignature"`
+					Timestamp    int64  `xml:"Timestamp"`
+					Nonce        string `xml:"Nonce"`
+				}
+				c.So(xml.Unmarshal(reqBody, &x), c.ShouldBeNil)
+
+				u := fmt.Sprintf(
+					"%s/test?msg_signature=%s&timestamp=%d&nonce=%s",
+					server.URL,
+					x.MsgSignature,
+					x.Timestamp,
+					x.Nonce,
+				)
+				resp, err := http.DefaultClient.Post(u, "application/xml", bytes.NewReader(reqBody))
+				c.So(err, c.ShouldBeNil)
+				return resp
+			}
+
+			c.Convey("无需被动回复时返回空响应", func() {
+				resp := post("noreply")
+				defer resp.Body.Close()
+				c.So(resp.StatusCode, c.ShouldEqual, http.StatusOK)
+
+				body, err := ioutil.ReadAll(resp.Body)
+				c.So(err, c.ShouldBeNil)
+				c.So(body, c.ShouldBeEmpty)
+			})
+
+			c.Convey("处理出错时按错误返回相应状态码", func() {
+				resp := post("fail")
+				resp.Body.Close()
+				c.So(resp.StatusCode, c.ShouldEqual, http.StatusInternalServerError)
+
+				resp = post("busy")
+				resp.Body.Close()
+				c.So(resp.StatusCode, c.ShouldEqual, http.StatusServiceUnavailable)
+			})
+
+			c.Convey("被动回复应该被加密后返回", func() {
+				resp := post("hello")
+				defer resp.Body.Close()
+				c.So(resp.StatusCode, c.ShouldEqual, http.StatusOK)
+
+				body, err := ioutil.ReadAll(resp.Body)
+				c.So(err, c.ShouldBeNil)
+
+				var x struct {
+					MsgSignature string `xml:"MsgSignature"`
+					Timestamp    int64  `xml:"Timestamp"`
+					Nonce        string `xml:"Nonce"`
+				}
+				c.So(xml.Unmarshal(body, &x), c.ShouldBeNil)
+
+				u, err := url.Parse(fmt.Sprintf(
+					"http://a.b/?msg_signature=%s&timestamp=%d&nonce=%s",
+					x.MsgSignature,
+					x.Timestamp,
+					x.Nonce,
+				))
+				c.So(err, c.ShouldBeNil)
+
+				rtt, err := pr.HandleIncomingMsg(u, body)
+				c.So(err, c.ShouldBeNil)
+				c.So(string(rtt.Msg), c.ShouldEqual, "reply to hello")
+				c.So(string(rtt.ReceiveID), c.ShouldEqual, "corpid")
+			})
+		})
+	})
+}
+
+// flakyEnvelopeHandler fails the first delivery of each message
+type flakyEnvelopeHandler struct {
+	seen map[string]int
+}
+
+func (h *flakyEnvelopeHandler) OnIncomingEnvelope(ctx context.Context, rx envelope.Envelope) ([]byte, error) {
+	h.seen[string(rx.Msg)]++
+	if h.seen[string(rx.Msg)] == 1 {
+		return nil, errors.New("fail")
+	}
+	return nil, nil
+}
+
+func TestLowlevelHandlerReplay(t *testing.T) {
+	c.Convey("防重放与重试", t, func() {
+		//nolint: gosec  // randomly generated for test purposes only
+		token := "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme"
+		encodingAESKey := "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws"
+		eh := &flakyEnvelopeHandler{seen: make(map[string]int)}
+		handler, err := NewLowLevelHandler(
+			token,
+			encodingAESKey,
+			eh,
+			envelope.WithTimestampWindow(5*time.Minute),
+			envelope.WithNonceCache(envelope.NewMemoryNonceCache(16)),
+		)
+		c.So(err, c.ShouldBeNil)
+
+		pr, err := envelope.NewProcessor(token, encodingAESKey)
+		c.So(err, c.ShouldBeNil)
+
+		makeRequest := func(msg string, ts int64, nonce string) func() int {
+			reqBody, err := pr.MakeOutgoingEnvelopeAt([]byte(msg), []byte("corpid"), ts, nonce)
+			c.So(err, c.ShouldBeNil)
+
+			var x struct {
+				MsgSignature string `xml:"MsgSignature"`
+			}
+			c.So(xml.Unmarshal(reqBody, &x), c.ShouldBeNil)
+
+			target := fmt.Sprintf("/test?msg_signature=%s&timestamp=%d&nonce=%s", x.MsgSignature, ts, nonce)
+			// sends the very same request each time, like WeCom retries
+			return func() int {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, bytes.NewReader(reqBody)))
+				return rec.Code
+			}
+		}
+
+		now := time.Now().Unix()
+
+		c.Convey("处理失败后的重试应该被接受", func() {
+			send := makeRequest("hello", now, "nonce1")
+			c.So(send(), c.ShouldEqual, http.StatusInternalServerError)
+			c.So(send(), c.ShouldEqual, http.StatusOK)
+			c.So(eh.seen["hello"], c.ShouldEqual, 2)
+
+			// handled successfully, so it's a replay now
+			c.So(send(), c.ShouldEqual, http.StatusConflict)
+			c.So(eh.seen["hello"], c.ShouldEqual, 2)
+		})
+
+		c.Convey("时间戳过期的请求以 403 拒绝", func() {
+			send := makeRequest("hello", now-3600, "nonce2")
+			c.So(send(), c.ShouldEqual, http.StatusForbidden)
+			c.So(eh.seen, c.ShouldBeEmpty)
+		})
 	})
 }
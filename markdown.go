@@ -0,0 +1,492 @@
+package workwx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// MarkdownMaxBytes 企业微信 Markdown 消息内容的最大字节数（UTF-8 编码）
+//
+// 超出部分会被服务端直接截断，因此发送前最好自行拆分或截断。
+const MarkdownMaxBytes = 4096
+
+// MarkdownTruncatedMarker 截断 Markdown 内容时追加的可见标记
+const MarkdownTruncatedMarker = "\n<font color=\"comment\">……（内容过长，已截断）</font>"
+
+// MarkdownFontColor Markdown 消息支持的字体颜色
+type MarkdownFontColor string
+
+const (
+	// MarkdownFontColorInfo 绿色
+	MarkdownFontColorInfo MarkdownFontColor = "info"
+	// MarkdownFontColorComment 灰色
+	MarkdownFontColorComment MarkdownFontColor = "comment"
+	// MarkdownFontColorWarning 橙红色
+	MarkdownFontColorWarning MarkdownFontColor = "warning"
+)
+
+func (c MarkdownFontColor) isValid() bool {
+	switch c {
+	case MarkdownFontColorInfo, MarkdownFontColorComment, MarkdownFontColorWarning:
+		return true
+	}
+	return false
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\\", "\\\\",
+	"*", "\\*",
+	"_", "\\_",
+	"[", "\\[",
+	"]", "\\]",
+	"(", "\\(",
+	")", "\\)",
+	"#", "\\#",
+	"`", "\\`",
+)
+
+// EscapeMarkdown 转义用户输入，使其在企业微信 Markdown 消息中按原样显示
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+var markdownURLEscaper = strings.NewReplacer(
+	" ", "%20",
+	"(", "%28",
+	")", "%29",
+	"<", "%3C",
+	">", "%3E",
+)
+
+// MarkdownBuilder 企业微信 Markdown 消息构造器
+//
+// 所有文本参数均会被转义，因此可以放心传入用户内容；生成的内容只会用到企业微信
+// 支持的 Markdown 子集。
+type MarkdownBuilder struct {
+	buf strings.Builder
+	// atLineStart 当前是否位于行首
+	atLineStart bool
+}
+
+// NewMarkdownBuilder 构造一个空的 MarkdownBuilder
+func NewMarkdownBuilder() *MarkdownBuilder {
+	return &MarkdownBuilder{atLineStart: true}
+}
+
+func (b *MarkdownBuilder) write(s string) *MarkdownBuilder {
+	if s == "" {
+		return b
+	}
+	b.buf.WriteString(s)
+	b.atLineStart = strings.HasSuffix(s, "\n")
+	return b
+}
+
+// ensureLineStart 如当前不在行首则先换行，用于标题、引用等块级元素
+func (b *MarkdownBuilder) ensureLineStart() {
+	if !b.atLineStart {
+		b.write("\n")
+	}
+}
+
+// Text 追加普通文本
+func (b *MarkdownBuilder) Text(text string) *MarkdownBuilder {
+	return b.write(EscapeMarkdown(text))
+}
+
+// Line 结束当前行
+func (b *MarkdownBuilder) Line() *MarkdownBuilder {
+	b.buf.WriteString("\n")
+	b.atLineStart = true
+	return b
+}
+
+// Heading 追加标题行，level 取值 1~6，超出范围将被修正
+func (b *MarkdownBuilder) Heading(level int, text string) *MarkdownBuilder {
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+
+	b.ensureLineStart()
+	b.write(strings.Repeat("#", level) + " " + EscapeMarkdown(singleLine(text)))
+	return b.Line()
+}
+
+// Bold 追加加粗文本
+func (b *MarkdownBuilder) Bold(text string) *MarkdownBuilder {
+	return b.write("**" + EscapeMarkdown(text) + "**")
+}
+
+// Link 追加链接
+func (b *MarkdownBuilder) Link(text string, url string) *MarkdownBuilder {
+	return b.write("[" + EscapeMarkdown(singleLine(text)) + "](" + markdownURLEscaper.Replace(url) + ")")
+}
+
+// Quote 追加引用行，多行文本的每一行都会成为引用
+func (b *MarkdownBuilder) Quote(text string) *MarkdownBuilder {
+	b.ensureLineStart()
+	for _, line := range strings.Split(text, "\n") {
+		b.write("> " + EscapeMarkdown(line))
+		b.Line()
+	}
+	return b
+}
+
+// FontColor 追加带颜色的文本，不支持的颜色将退化为普通文本
+func (b *MarkdownBuilder) FontColor(color MarkdownFontColor, text string) *MarkdownBuilder {
+	if !color.isValid() {
+		return b.Text(text)
+	}
+	return b.write(fmt.Sprintf("<font color=\"%s\">%s</font>", color, EscapeMarkdown(text)))
+}
+
+// Mention 追加对成员的提醒（`<@userid>`）
+func (b *MarkdownBuilder) Mention(userID string) *MarkdownBuilder {
+	return b.write("<@" + strings.NewReplacer("<", "", ">", "", "\n", "").Replace(userID) + ">")
+}
+
+// InlineCode 追加行内代码
+func (b *MarkdownBuilder) InlineCode(code string) *MarkdownBuilder {
+	return b.write("`" + strings.ReplaceAll(singleLine(code), "`", "'") + "`")
+}
+
+// Len 已构造内容的字节数
+func (b *MarkdownBuilder) Len() int {
+	return b.buf.Len()
+}
+
+// String 返回构造好的 Markdown 内容
+func (b *MarkdownBuilder) String() string {
+	return b.buf.String()
+}
+
+func singleLine(s string) string {
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// MarkdownValidationError Markdown 内容不符合企业微信支持子集时的错误
+type MarkdownValidationError struct {
+	// Line 出错的行号，从 1 开始；为 0 表示针对整体内容
+	Line int
+	// Reason 出错原因
+	Reason string
+}
+
+var _ error = (*MarkdownValidationError)(nil)
+
+func (e *MarkdownValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("invalid workwx markdown: %s", e.Reason)
+	}
+	return fmt.Sprintf("invalid workwx markdown: line %d: %s", e.Line, e.Reason)
+}
+
+var (
+	markdownTagRegexp       = regexp.MustCompile(`<(/?)([A-Za-z][A-Za-z0-9]*)([^<>]*)>`)
+	markdownFontOpenRegexp  = regexp.MustCompile(`^\s+color="([a-z]+)"\s*$`)
+	markdownImageRegexp     = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownTableSepRegexp  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)+\|?\s*$`)
+	markdownHeadingRegexp   = regexp.MustCompile(`^(#+)\s`)
+	markdownCodeFenceRegexp = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// ValidateMarkdown 校验内容是否符合企业微信支持的 Markdown 子集
+//
+// 支持标题（1~6 级）、加粗、链接、引用、`<font color="info|comment|warning">`
+// 与 `<@userid>` 提醒；图片、代码块、表格及其他 HTML 标签均不受支持。内容长度
+// 不能超过 MarkdownMaxBytes。
+func ValidateMarkdown(content string) error {
+	if !utf8.ValidString(content) {
+		return &MarkdownValidationError{Reason: "content is not valid UTF-8"}
+	}
+	if len(content) > MarkdownMaxBytes {
+		return &MarkdownValidationError{
+			Reason: fmt.Sprintf("content is %d bytes, exceeding limit of %d", len(content), MarkdownMaxBytes),
+		}
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		lineno := i + 1
+
+		if markdownCodeFenceRegexp.MatchString(line) {
+			return &MarkdownValidationError{Line: lineno, Reason: "fenced code blocks are not supported"}
+		}
+		if markdownTableSepRegexp.MatchString(line) {
+			return &MarkdownValidationError{Line: lineno, Reason: "tables are not supported"}
+		}
+		if m := markdownHeadingRegexp.FindStringSubmatch(line); m != nil && len(m[1]) > 6 {
+			return &MarkdownValidationError{Line: lineno, Reason: "heading level exceeds 6"}
+		}
+		if markdownImageRegexp.MatchString(line) {
+			return &MarkdownValidationError{Line: lineno, Reason: "images are not supported"}
+		}
+
+		for _, m := range markdownTagRegexp.FindAllStringSubmatch(line, -1) {
+			isClose, name, attrs := m[1] == "/", strings.ToLower(m[2]), m[3]
+			if name != "font" {
+				return &MarkdownValidationError{
+					Line:   lineno,
+					Reason: fmt.Sprintf("HTML tag <%s> is not supported", name),
+				}
+			}
+			if isClose {
+				continue
+			}
+
+			attrMatch := markdownFontOpenRegexp.FindStringSubmatch(attrs)
+			if attrMatch == nil || !MarkdownFontColor(attrMatch[1]).isValid() {
+				return &MarkdownValidationError{
+					Line:   lineno,
+					Reason: fmt.Sprintf("unsupported font attributes %q", strings.TrimSpace(attrs)),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// TruncateMarkdown 将内容截断到不超过 maxBytes 字节
+//
+// 截断优先发生在行边界，单行过长时则在 UTF-8 字符边界截断，且不会截断在 HTML
+// 标签内部；发生截断时会在末尾追加 MarkdownTruncatedMarker。maxBytes 不大于 0 时
+// 使用 MarkdownMaxBytes。
+func TruncateMarkdown(content string, maxBytes int) string {
+	if maxBytes <= 0 {
+		maxBytes = MarkdownMaxBytes
+	}
+	if len(content) <= maxBytes {
+		return content
+	}
+
+	budget := maxBytes - len(MarkdownTruncatedMarker)
+	if budget <= 0 {
+		// 连标记都放不下，只好直接截断
+		return cutAtRuneBoundary(content, maxBytes)
+	}
+
+	return strings.TrimRight(cutMarkdownAt(content, budget), "\n") + MarkdownTruncatedMarker
+}
+
+// markdownSplitMarkerReserve 为拆分后每段末尾的 "(i/n)" 标记预留的字节数
+const markdownSplitMarkerReserve = 16
+
+// SplitMarkdown 将内容拆分为若干段，每段均不超过 maxBytes 字节
+//
+// 拆分优先发生在行边界，单行过长时则在 UTF-8 字符边界拆分，且不会拆分在 HTML
+// 标签内部；拆分出多段时，每段末尾会追加形如 "(1/3)" 的可见标记。maxBytes 不大于
+// 0 时使用 MarkdownMaxBytes。
+func SplitMarkdown(content string, maxBytes int) []string {
+	if maxBytes <= 0 {
+		maxBytes = MarkdownMaxBytes
+	}
+	if len(content) <= maxBytes {
+		return []string{content}
+	}
+
+	budget := maxBytes - markdownSplitMarkerReserve
+	if budget <= 0 {
+		// 预算过小，放弃拆分标记
+		budget = maxBytes
+	}
+
+	var chunks []string
+	rest := content
+	for rest != "" {
+		chunk := cutMarkdownAt(rest, budget)
+		if chunk == "" {
+			// 无法在预算内找到合法的截断点（如超长的 HTML 标签），只好硬切
+			chunk = cutAtRuneBoundary(rest, budget)
+		}
+		if chunk == "" {
+			// 预算连一个字符都放不下
+			_, size := utf8.DecodeRuneInString(rest)
+			chunk = rest[:size]
+		}
+		rest = rest[len(chunk):]
+		if trimmed := strings.TrimRight(chunk, "\n"); trimmed != "" {
+			chunks = append(chunks, trimmed)
+		}
+	}
+
+	if budget == maxBytes {
+		return chunks
+	}
+	for i := range chunks {
+		chunks[i] += fmt.Sprintf("\n(%d/%d)", i+1, len(chunks))
+	}
+	return chunks
+}
+
+// cutMarkdownAt 返回 content 的一个不超过 maxBytes 字节的前缀，尽量在行边界截断
+func cutMarkdownAt(content string, maxBytes int) string {
+	if len(content) <= maxBytes {
+		return content
+	}
+
+	// 行边界：保留换行符本身，以便调用方区分
+	if idx := strings.LastIndexByte(content[:maxBytes], '\n'); idx > 0 {
+		return content[:idx+1]
+	}
+
+	prefix := cutAtRuneBoundary(content, maxBytes)
+
+	// 不要截断在 HTML 标签（或 <@userid> 提醒）内部
+	if lt := strings.LastIndexByte(prefix, '<'); lt >= 0 && strings.IndexByte(prefix[lt:], '>') < 0 {
+		prefix = prefix[:lt]
+	}
+
+	return prefix
+}
+
+// cutAtRuneBoundary 返回 s 的一个不超过 maxBytes 字节、且不截断 UTF-8 字符的前缀
+func cutAtRuneBoundary(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
+// MarkdownFromCommonMark 将 CommonMark（的常用子集）转换为企业微信 Markdown
+//
+// 企业微信不支持的元素会被降级：斜体变为普通文本，图片变为链接，代码块的每行
+// 变为行内代码，列表与表格行变为普通文本行。
+func MarkdownFromCommonMark(src []byte) string {
+	md := blackfriday.New(blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	root := md.Parse(src)
+
+	var sb strings.Builder
+	renderCommonMarkBlocks(&sb, root)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func renderCommonMarkBlocks(sb *strings.Builder, parent *blackfriday.Node) {
+	for n := parent.FirstChild; n != nil; n = n.Next {
+		renderCommonMarkBlock(sb, n)
+	}
+}
+
+func renderCommonMarkBlock(sb *strings.Builder, n *blackfriday.Node) {
+	switch n.Type {
+	case blackfriday.Paragraph:
+		sb.WriteString(renderCommonMarkInlines(n))
+		sb.WriteString("\n")
+		if n.Parent == nil || n.Parent.Type != blackfriday.Item {
+			sb.WriteString("\n")
+		}
+
+	case blackfriday.Heading:
+		level := n.HeadingData.Level
+		if level > 6 {
+			level = 6
+		}
+		sb.WriteString(strings.Repeat("#", level) + " " + renderCommonMarkInlines(n) + "\n\n")
+
+	case blackfriday.BlockQuote:
+		var inner strings.Builder
+		renderCommonMarkBlocks(&inner, n)
+		for _, line := range strings.Split(strings.TrimRight(inner.String(), "\n"), "\n") {
+			sb.WriteString("> " + line + "\n")
+		}
+		sb.WriteString("\n")
+
+	case blackfriday.List:
+		idx := 1
+		for item := n.FirstChild; item != nil; item = item.Next {
+			var inner strings.Builder
+			renderCommonMarkBlocks(&inner, item)
+
+			bullet := "- "
+			if n.ListData.ListFlags&blackfriday.ListTypeOrdered != 0 {
+				bullet = fmt.Sprintf("%d. ", idx)
+				idx++
+			}
+			for i, line := range strings.Split(strings.TrimRight(inner.String(), "\n"), "\n") {
+				if i == 0 {
+					sb.WriteString(bullet + line + "\n")
+				} else {
+					sb.WriteString(strings.Repeat(" ", len(bullet)) + line + "\n")
+				}
+			}
+		}
+		if n.Parent == nil || n.Parent.Type != blackfriday.Item {
+			sb.WriteString("\n")
+		}
+
+	case blackfriday.CodeBlock:
+		for _, line := range strings.Split(strings.TrimRight(string(n.Literal), "\n"), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			sb.WriteString("`" + strings.ReplaceAll(line, "`", "'") + "`\n")
+		}
+		sb.WriteString("\n")
+
+	case blackfriday.Table:
+		for section := n.FirstChild; section != nil; section = section.Next {
+			for row := section.FirstChild; row != nil; row = row.Next {
+				var cells []string
+				for cell := row.FirstChild; cell != nil; cell = cell.Next {
+					text := renderCommonMarkInlines(cell)
+					if cell.TableCellData.IsHeader {
+						text = "**" + text + "**"
+					}
+					cells = append(cells, text)
+				}
+				sb.WriteString(strings.Join(cells, " / ") + "\n")
+			}
+		}
+		sb.WriteString("\n")
+
+	case blackfriday.HTMLBlock:
+		sb.WriteString(EscapeMarkdown(strings.TrimRight(string(n.Literal), "\n")) + "\n\n")
+
+	case blackfriday.HorizontalRule:
+		// 不支持，忽略
+
+	default:
+		renderCommonMarkBlocks(sb, n)
+	}
+}
+
+func renderCommonMarkInlines(parent *blackfriday.Node) string {
+	var sb strings.Builder
+	for n := parent.FirstChild; n != nil; n = n.Next {
+		switch n.Type {
+		case blackfriday.Text:
+			sb.WriteString(EscapeMarkdown(string(n.Literal)))
+		case blackfriday.Strong:
+			sb.WriteString("**" + renderCommonMarkInlines(n) + "**")
+		case blackfriday.Emph, blackfriday.Del:
+			sb.WriteString(renderCommonMarkInlines(n))
+		case blackfriday.Link:
+			sb.WriteString("[" + renderCommonMarkInlines(n) + "](" + markdownURLEscaper.Replace(string(n.LinkData.Destination)) + ")")
+		case blackfriday.Image:
+			sb.WriteString("[" + renderCommonMarkInlines(n) + "](" + markdownURLEscaper.Replace(string(n.LinkData.Destination)) + ")")
+		case blackfriday.Code:
+			sb.WriteString("`" + strings.ReplaceAll(string(n.Literal), "`", "'") + "`")
+		case blackfriday.Softbreak, blackfriday.Hardbreak:
+			sb.WriteString("\n")
+		case blackfriday.HTMLSpan:
+			sb.WriteString(EscapeMarkdown(string(n.Literal)))
+		default:
+			sb.WriteString(renderCommonMarkInlines(n))
+		}
+	}
+	return sb.String()
+}
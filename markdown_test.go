@@ -0,0 +1,156 @@
+package workwx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMarkdownBuilder(t *testing.T) {
+	c.Convey("MarkdownBuilder", t, func() {
+		c.Convey("构造各种元素", func() {
+			b := NewMarkdownBuilder()
+			b.Heading(2, "告警").
+				Text("服务 ").Bold("api").Text(" 出现异常，").
+				FontColor(MarkdownFontColorWarning, "请尽快处理").Line().
+				Quote("错误率 5%").
+				Link("详情", "https://example.com/a b").Text(" ").Mention("zhangsan")
+
+			expected := "## 告警\n" +
+				"服务 **api** 出现异常，<font color=\"warning\">请尽快处理</font>\n" +
+				"> 错误率 5%\n" +
+				"[详情](https://example.com/a%20b) <@zhangsan>"
+			c.So(b.String(), c.ShouldEqual, expected)
+			c.So(ValidateMarkdown(b.String()), c.ShouldBeNil)
+		})
+
+		c.Convey("用户内容应该被转义", func() {
+			b := NewMarkdownBuilder()
+			b.Text(`<font color="info">*x*</font> [a](b) #1`)
+
+			c.So(b.String(), c.ShouldEqual, `&lt;font color="info"&gt;\*x\*&lt;/font&gt; \[a\]\(b\) \#1`)
+			c.So(ValidateMarkdown(b.String()), c.ShouldBeNil)
+		})
+
+		c.Convey("标题等块级元素应该另起一行", func() {
+			b := NewMarkdownBuilder()
+			b.Text("a").Heading(9, "b").Quote("c\nd")
+
+			c.So(b.String(), c.ShouldEqual, "a\n###### b\n> c\n> d\n")
+		})
+
+		c.Convey("不支持的颜色退化为普通文本", func() {
+			b := NewMarkdownBuilder()
+			b.FontColor(MarkdownFontColor("red"), "x")
+
+			c.So(b.String(), c.ShouldEqual, "x")
+		})
+	})
+}
+
+func TestValidateMarkdown(t *testing.T) {
+	c.Convey("ValidateMarkdown", t, func() {
+		c.Convey("合法内容", func() {
+			content := "# 标题\n**粗体** [链接](https://example.com)\n> 引用\n<font color=\"comment\">灰色</font> <@lisi>"
+			c.So(ValidateMarkdown(content), c.ShouldBeNil)
+		})
+
+		cases := []struct {
+			name    string
+			content string
+			line    int
+		}{
+			{"图片", "ok\n![img](https://example.com/a.png)", 2},
+			{"代码块", "ok\nok\n```go", 3},
+			{"表格", "| a | b |\n|---|---|", 2},
+			{"其他 HTML 标签", "<b>x</b>", 1},
+			{"不支持的颜色", "<font color=\"red\">x</font>", 1},
+			{"过深的标题", "####### x", 1},
+			{"超长内容", strings.Repeat("a", MarkdownMaxBytes+1), 0},
+		}
+		for _, tc := range cases {
+			tc := tc
+			c.Convey("非法内容："+tc.name, func() {
+				err := ValidateMarkdown(tc.content)
+				c.So(err, c.ShouldNotBeNil)
+
+				verr, ok := err.(*MarkdownValidationError)
+				c.So(ok, c.ShouldBeTrue)
+				c.So(verr.Line, c.ShouldEqual, tc.line)
+			})
+		}
+	})
+}
+
+func TestTruncateAndSplitMarkdown(t *testing.T) {
+	c.Convey("截断与拆分", t, func() {
+		c.Convey("未超长的内容原样返回", func() {
+			c.So(TruncateMarkdown("abc", 10), c.ShouldEqual, "abc")
+			c.So(SplitMarkdown("abc", 10), c.ShouldResemble, []string{"abc"})
+		})
+
+		c.Convey("截断在行边界并追加标记", func() {
+			content := strings.Repeat("第一行\n", 100)
+			result := TruncateMarkdown(content, 200)
+
+			c.So(len(result), c.ShouldBeLessThanOrEqualTo, 200)
+			c.So(result, c.ShouldEndWith, MarkdownTruncatedMarker)
+			c.So(strings.TrimSuffix(result, MarkdownTruncatedMarker), c.ShouldEndWith, "第一行")
+		})
+
+		c.Convey("单行过长时在 UTF-8 字符边界截断", func() {
+			content := strings.Repeat("中", 1000)
+			result := TruncateMarkdown(content, 100)
+
+			c.So(len(result), c.ShouldBeLessThanOrEqualTo, 100)
+			c.So(utf8.ValidString(result), c.ShouldBeTrue)
+		})
+
+		c.Convey("不截断在标签内部", func() {
+			content := strings.Repeat("a", 40) + "<font color=\"info\">x</font>"
+			result := cutMarkdownAt(content, 50)
+
+			c.So(result, c.ShouldEqual, strings.Repeat("a", 40))
+		})
+
+		c.Convey("拆分出多段时每段都带有序号", func() {
+			content := strings.Repeat("一二三四五六七八九十\n", 50)
+			chunks := SplitMarkdown(content, 200)
+
+			c.So(len(chunks), c.ShouldBeGreaterThan, 1)
+			var rebuilt []string
+			for i, chunk := range chunks {
+				c.So(len(chunk), c.ShouldBeLessThanOrEqualTo, 200)
+				c.So(utf8.ValidString(chunk), c.ShouldBeTrue)
+
+				marker := fmt.Sprintf("\n(%d/%d)", i+1, len(chunks))
+				c.So(chunk, c.ShouldEndWith, marker)
+				rebuilt = append(rebuilt, strings.TrimSuffix(chunk, marker))
+			}
+			c.So(strings.Join(rebuilt, "\n")+"\n", c.ShouldEqual, content)
+		})
+	})
+}
+
+func TestMarkdownFromCommonMark(t *testing.T) {
+	c.Convey("CommonMark 转换", t, func() {
+		src := "# Title\n\nHello *world* and **bold** with `code`.\n\n" +
+			"> quoted\n\n" +
+			"- a\n- b\n\n" +
+			"![alt](https://example.com/x.png)\n\n" +
+			"```\nfoo()\n```\n"
+		result := MarkdownFromCommonMark([]byte(src))
+
+		expected := "# Title\n\n" +
+			"Hello world and **bold** with `code`.\n\n" +
+			"> quoted\n\n" +
+			"- a\n- b\n\n" +
+			"[alt](https://example.com/x.png)\n\n" +
+			"`foo()`"
+		c.So(result, c.ShouldEqual, expected)
+		c.So(ValidateMarkdown(result), c.ShouldBeNil)
+	})
+}
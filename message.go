@@ -118,6 +118,8 @@ func (c *WorkwxApp) SendTextCardMessage(
 
 // SendNewsMessage 发送图文消息
 //
+// 仅发送一篇图文；如需发送多篇图文，请使用 SendNewsArticles。
+//
 // 收件人参数如果仅设置了 `ChatID` 字段，则为【发送消息到群聊会话】接口调用；
 // 否则为单纯的【发送应用消息】接口调用。
 func (c *WorkwxApp) SendNewsMessage(
@@ -128,20 +130,47 @@ func (c *WorkwxApp) SendNewsMessage(
 	picURL string,
 	isSafe bool,
 ) error {
+	return c.SendNewsArticles(
+		recipient,
+		[]NewsArticle{
+			{
+				Title:       title,
+				Description: description,
+				URL:         url,
+				PicURL:      picURL,
+			},
+		}, isSafe,
+	)
+}
+
+// SendNewsArticles 发送包含多篇图文的图文消息
+//
+// 一条图文消息支持 1 到 8 篇图文，发送前会校验各篇图文的字段长度等约束。
+//
+// 收件人参数如果仅设置了 `ChatID` 字段，则为【发送消息到群聊会话】接口调用；
+// 否则为单纯的【发送应用消息】接口调用。
+func (c *WorkwxApp) SendNewsArticles(
+	recipient *Recipient,
+	articles []NewsArticle,
+	isSafe bool,
+) error {
+	if err := validateNewsArticles(articles); err != nil {
+		return err
+	}
+
 	return c.sendMessage(
 		recipient,
 		"news",
 		map[string]interface{}{
-			"title":       title,
-			"description": description, // TODO: 零值
-			"url":         url,
-			"picurl":      picURL, // TODO: 零值
+			"articles": articles,
 		}, isSafe,
 	)
 }
 
 // SendMPNewsMessage 发送 mpnews 类型的图文消息
 //
+// 仅发送一篇图文；如需发送多篇图文，请使用 SendMPNewsArticles。
+//
 // 收件人参数如果仅设置了 `ChatID` 字段，则为【发送消息到群聊会话】接口调用；
 // 否则为单纯的【发送应用消息】接口调用。
 func (c *WorkwxApp) SendMPNewsMessage(
@@ -154,21 +183,41 @@ func (c *WorkwxApp) SendMPNewsMessage(
 	digest string,
 	isSafe bool,
 ) error {
+	return c.SendMPNewsArticles(
+		recipient,
+		[]MPNewsArticle{
+			{
+				Title:            title,
+				ThumbMediaID:     thumbMediaID,
+				Author:           author,
+				ContentSourceURL: sourceContentURL,
+				Content:          content,
+				Digest:           digest,
+			},
+		}, isSafe,
+	)
+}
+
+// SendMPNewsArticles 发送包含多篇图文的 mpnews 类型图文消息
+//
+// 一条图文消息支持 1 到 8 篇图文，发送前会校验各篇图文的必填字段与字段长度等约束。
+//
+// 收件人参数如果仅设置了 `ChatID` 字段，则为【发送消息到群聊会话】接口调用；
+// 否则为单纯的【发送应用消息】接口调用。
+func (c *WorkwxApp) SendMPNewsArticles(
+	recipient *Recipient,
+	articles []MPNewsArticle,
+	isSafe bool,
+) error {
+	if err := validateMPNewsArticles(articles); err != nil {
+		return err
+	}
+
 	return c.sendMessage(
 		recipient,
 		"mpnews",
 		map[string]interface{}{
-			// TODO: 支持发送多条图文
-			"articles": []interface{}{
-				map[string]interface{}{
-					"title":              title,
-					"thumb_media_id":     thumbMediaID,
-					"author":             author,           // TODO: 零值
-					"content_source_url": sourceContentURL, // TODO: 零值
-					"content":            content,
-					"digest":             digest,
-				},
-			},
+			"articles": articles,
 		}, isSafe,
 	)
 }
@@ -176,6 +225,8 @@ func (c *WorkwxApp) SendMPNewsMessage(
 // SendMarkdownMessage 发送 Markdown 消息
 //
 // 仅支持 Markdown 的子集，详见[官方文档](https://work.weixin.qq.com/api/doc#90002/90151/90854/%E6%94%AF%E6%8C%81%E7%9A%84markdown%E8%AF%AD%E6%B3%95)。
+// 内容超过 MarkdownMaxBytes 字节会被服务端截断；可用 MarkdownBuilder 构造、
+// ValidateMarkdown 校验内容，用 SplitMarkdown 或 TruncateMarkdown 处理超长内容。
 //
 // 收件人参数如果仅设置了 `ChatID` 字段，则为【发送消息到群聊会话】接口调用；
 // 否则为单纯的【发送应用消息】接口调用。
@@ -187,6 +238,41 @@ func (c *WorkwxApp) SendMarkdownMessage(
 	return c.sendMessage(recipient, "markdown", map[string]interface{}{"content": content}, isSafe)
 }
 
+// SendMiniprogramNoticeMessage 发送小程序通知消息
+//
+// 小程序通知消息只允许绑定了小程序的应用发送，且不支持发送到群聊会话，因此收件人
+// 参数不可仅设置 `ChatID` 字段；消息内容最多 10 行。
+func (c *WorkwxApp) SendMiniprogramNoticeMessage(
+	recipient *Recipient,
+	appID string,
+	page string,
+	title string,
+	description string,
+	emphasisFirstItem bool,
+	contentItems []MiniprogramNoticeContentItem,
+) error {
+	if err := validateMiniprogramNotice(recipient, appID, title, contentItems); err != nil {
+		return err
+	}
+
+	content := map[string]interface{}{
+		"appid":               appID,
+		"title":               title,
+		"emphasis_first_item": emphasisFirstItem,
+	}
+	if page != "" {
+		content["page"] = page
+	}
+	if description != "" {
+		content["description"] = description
+	}
+	if len(contentItems) > 0 {
+		content["content_item"] = contentItems
+	}
+
+	return c.sendMessage(recipient, "miniprogram_notice", content, false)
+}
+
 // SendTaskCardMessage 发送 任务卡片 消息
 func (c *WorkwxApp) SendTaskCardMessage(
 	recipient *Recipient,
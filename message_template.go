@@ -0,0 +1,150 @@
+package workwx
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// TemplatedMessageKind 模板消息渲染后的消息类型
+type TemplatedMessageKind string
+
+const (
+	// TemplatedMessageKindText 文本消息
+	TemplatedMessageKindText TemplatedMessageKind = "text"
+	// TemplatedMessageKindMarkdown markdown 消息
+	TemplatedMessageKindMarkdown TemplatedMessageKind = "markdown"
+)
+
+// maxUserIDsPerMessage 单条应用消息最多支持的成员数
+const maxUserIDsPerMessage = 1000
+
+// TemplatedMessageBatch 渲染结果相同的一批收件人
+type TemplatedMessageBatch struct {
+	// Content 渲染后的消息内容
+	Content string
+	// UserIDs 收到该内容的成员ID列表，已排序，且不超过单条消息的收件人上限
+	UserIDs []string
+}
+
+// RenderTemplatedMessages 为每个成员渲染模板，并将渲染结果相同的成员合并为一批
+//
+// data 为成员ID到模板数据的映射。每批的成员数不超过单条消息的收件人上限（1000），
+// 超出时会拆分为多批；返回的批次顺序是确定的。
+//
+// 本函数不发送任何消息，可用于发送前预览（dry run）。
+func RenderTemplatedMessages(
+	tmpl *template.Template,
+	data map[string]interface{},
+) ([]TemplatedMessageBatch, error) {
+	userIDs := make([]string, 0, len(data))
+	for userID := range data {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	var contents []string
+	grouped := make(map[string][]string)
+	var buf bytes.Buffer
+	for _, userID := range userIDs {
+		buf.Reset()
+		err := tmpl.Execute(&buf, data[userID])
+		if err != nil {
+			return nil, fmt.Errorf("rendering message for user %s: %w", userID, err)
+		}
+
+		content := buf.String()
+		if _, ok := grouped[content]; !ok {
+			contents = append(contents, content)
+		}
+		grouped[content] = append(grouped[content], userID)
+	}
+
+	var result []TemplatedMessageBatch
+	for _, content := range contents {
+		ids := grouped[content]
+		for len(ids) > 0 {
+			n := len(ids)
+			if n > maxUserIDsPerMessage {
+				n = maxUserIDsPerMessage
+			}
+
+			result = append(result, TemplatedMessageBatch{
+				Content: content,
+				UserIDs: ids[:n],
+			})
+			ids = ids[n:]
+		}
+	}
+
+	return result, nil
+}
+
+// TemplatedMessageSendError 模板消息部分批次发送失败时的错误
+type TemplatedMessageSendError struct {
+	// FailedUserIDs 所在批次发送失败的成员ID列表
+	FailedUserIDs []string
+	// Errs 各失败批次的错误
+	Errs []error
+}
+
+var _ error = (*TemplatedMessageSendError)(nil)
+
+func (e *TemplatedMessageSendError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf(
+		"templated message sending failed for %d user(s): %s",
+		len(e.FailedUserIDs),
+		strings.Join(msgs, "; "),
+	)
+}
+
+// SendTemplatedMessage 按成员渲染模板并发送消息
+//
+// 渲染结果相同的成员会被合并到同一次【发送应用消息】接口调用中，详见
+// RenderTemplatedMessages。某一批发送失败不影响其余批次的发送，全部批次处理完后
+// 以 *TemplatedMessageSendError 报告失败的成员；模板渲染出错时则不发送任何消息。
+func (c *WorkwxApp) SendTemplatedMessage(
+	kind TemplatedMessageKind,
+	tmpl *template.Template,
+	data map[string]interface{},
+	isSafe bool,
+) error {
+	switch kind {
+	case TemplatedMessageKindText, TemplatedMessageKindMarkdown:
+	default:
+		return fmt.Errorf("unsupported templated message kind: %s", kind)
+	}
+
+	batches, err := RenderTemplatedMessages(tmpl, data)
+	if err != nil {
+		return err
+	}
+
+	var sendErr TemplatedMessageSendError
+	for _, batch := range batches {
+		recipient := Recipient{UserIDs: batch.UserIDs}
+		err := c.sendMessage(
+			&recipient,
+			string(kind),
+			map[string]interface{}{"content": batch.Content},
+			isSafe,
+		)
+		if err != nil {
+			sendErr.FailedUserIDs = append(sendErr.FailedUserIDs, batch.UserIDs...)
+			sendErr.Errs = append(sendErr.Errs, err)
+		}
+	}
+
+	if len(sendErr.Errs) > 0 {
+		return &sendErr
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package workwx
+
+import (
+	"fmt"
+	"testing"
+	"text/template"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRenderTemplatedMessages(t *testing.T) {
+	c.Convey("模板消息渲染", t, func() {
+		tmpl := template.Must(template.New("").Parse("{{.Name}}，您有 {{.Amount}} 元账单待支付"))
+
+		type row struct {
+			Name   string
+			Amount int
+		}
+
+		c.Convey("渲染结果相同的成员应该被合并", func() {
+			data := map[string]interface{}{
+				"c": row{"同学", 100},
+				"a": row{"同学", 100},
+				"b": row{"老师", 200},
+			}
+
+			batches, err := RenderTemplatedMessages(tmpl, data)
+			c.So(err, c.ShouldBeNil)
+			c.So(batches, c.ShouldResemble, []TemplatedMessageBatch{
+				{Content: "同学，您有 100 元账单待支付", UserIDs: []string{"a", "c"}},
+				{Content: "老师，您有 200 元账单待支付", UserIDs: []string{"b"}},
+			})
+		})
+
+		c.Convey("超过单条消息收件人上限时应该拆分", func() {
+			data := make(map[string]interface{})
+			for i := 0; i < 2500; i++ {
+				data[fmt.Sprintf("u%04d", i)] = row{"同学", 1}
+			}
+
+			batches, err := RenderTemplatedMessages(tmpl, data)
+			c.So(err, c.ShouldBeNil)
+			c.So(len(batches), c.ShouldEqual, 3)
+			c.So(len(batches[0].UserIDs), c.ShouldEqual, 1000)
+			c.So(len(batches[1].UserIDs), c.ShouldEqual, 1000)
+			c.So(len(batches[2].UserIDs), c.ShouldEqual, 500)
+			c.So(batches[2].UserIDs[499], c.ShouldEqual, "u2499")
+		})
+
+		c.Convey("渲染出错时应该指明成员", func() {
+			bad := template.Must(template.New("").Option("missingkey=error").Parse("{{.missing}}"))
+			_, err := RenderTemplatedMessages(bad, map[string]interface{}{
+				"foo": map[string]interface{}{},
+			})
+			c.So(err, c.ShouldNotBeNil)
+			c.So(err.Error(), c.ShouldContainSubstring, "foo")
+		})
+	})
+}
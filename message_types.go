@@ -0,0 +1,168 @@
+package workwx
+
+// Message 一条可发送的消息
+//
+// 本接口仅由本包内的消息类型实现，如 TextMessage、MarkdownMessage 等。
+type Message interface {
+	// msgType 消息类型，即请求中的 msgtype 字段
+	msgType() string
+	// intoContent 校验并转换为请求中对应消息类型的字段内容
+	intoContent() (map[string]interface{}, error)
+}
+
+// TextMessage 文本消息
+type TextMessage struct {
+	// Content 消息内容，最长不超过2048个字节，超过将截断
+	Content string
+}
+
+var _ Message = TextMessage{}
+
+func (m TextMessage) msgType() string { return "text" }
+
+func (m TextMessage) intoContent() (map[string]interface{}, error) {
+	return map[string]interface{}{"content": m.Content}, nil
+}
+
+// ImageMessage 图片消息
+type ImageMessage struct {
+	// MediaID 图片媒体文件id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ Message = ImageMessage{}
+
+func (m ImageMessage) msgType() string { return "image" }
+
+func (m ImageMessage) intoContent() (map[string]interface{}, error) {
+	return map[string]interface{}{"media_id": m.MediaID}, nil
+}
+
+// VoiceMessage 语音消息
+type VoiceMessage struct {
+	// MediaID 语音文件id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ Message = VoiceMessage{}
+
+func (m VoiceMessage) msgType() string { return "voice" }
+
+func (m VoiceMessage) intoContent() (map[string]interface{}, error) {
+	return map[string]interface{}{"media_id": m.MediaID}, nil
+}
+
+// VideoMessage 视频消息
+type VideoMessage struct {
+	// MediaID 视频媒体文件id，可以调用上传临时素材接口获取
+	MediaID string
+	// Title 视频消息的标题，不超过128个字节，超过会自动截断
+	Title string
+	// Description 视频消息的描述，不超过512个字节，超过会自动截断
+	Description string
+}
+
+var _ Message = VideoMessage{}
+
+func (m VideoMessage) msgType() string { return "video" }
+
+func (m VideoMessage) intoContent() (map[string]interface{}, error) {
+	obj := map[string]interface{}{"media_id": m.MediaID}
+	if m.Title != "" {
+		obj["title"] = m.Title
+	}
+	if m.Description != "" {
+		obj["description"] = m.Description
+	}
+	return obj, nil
+}
+
+// FileMessage 文件消息
+type FileMessage struct {
+	// MediaID 文件id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ Message = FileMessage{}
+
+func (m FileMessage) msgType() string { return "file" }
+
+func (m FileMessage) intoContent() (map[string]interface{}, error) {
+	return map[string]interface{}{"media_id": m.MediaID}, nil
+}
+
+// TextCardMessage 文本卡片消息
+type TextCardMessage struct {
+	// Title 标题，不超过128个字节，超过会自动截断
+	Title string
+	// Description 描述，不超过512个字节，超过会自动截断
+	Description string
+	// URL 点击后跳转的链接
+	URL string
+	// ButtonText 按钮文字。 默认为“详情”， 不超过4个文字，超过自动截断
+	ButtonText string
+}
+
+var _ Message = TextCardMessage{}
+
+func (m TextCardMessage) msgType() string { return "textcard" }
+
+func (m TextCardMessage) intoContent() (map[string]interface{}, error) {
+	obj := map[string]interface{}{
+		"title":       m.Title,
+		"description": m.Description,
+		"url":         m.URL,
+	}
+	if m.ButtonText != "" {
+		obj["btntxt"] = m.ButtonText
+	}
+	return obj, nil
+}
+
+// NewsMessage 图文消息
+type NewsMessage struct {
+	// Articles 图文列表，支持1到8篇图文
+	Articles []NewsArticle
+}
+
+var _ Message = NewsMessage{}
+
+func (m NewsMessage) msgType() string { return "news" }
+
+func (m NewsMessage) intoContent() (map[string]interface{}, error) {
+	if err := validateNewsArticles(m.Articles); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"articles": m.Articles}, nil
+}
+
+// MPNewsMessage mpnews 类型的图文消息
+type MPNewsMessage struct {
+	// Articles 图文列表，支持1到8篇图文
+	Articles []MPNewsArticle
+}
+
+var _ Message = MPNewsMessage{}
+
+func (m MPNewsMessage) msgType() string { return "mpnews" }
+
+func (m MPNewsMessage) intoContent() (map[string]interface{}, error) {
+	if err := validateMPNewsArticles(m.Articles); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"articles": m.Articles}, nil
+}
+
+// MarkdownMessage markdown 消息
+type MarkdownMessage struct {
+	// Content markdown内容，仅支持 Markdown 的子集，详见 SendMarkdownMessage
+	Content string
+}
+
+var _ Message = MarkdownMessage{}
+
+func (m MarkdownMessage) msgType() string { return "markdown" }
+
+func (m MarkdownMessage) intoContent() (map[string]interface{}, error) {
+	return map[string]interface{}{"content": m.Content}, nil
+}
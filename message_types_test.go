@@ -0,0 +1,52 @@
+package workwx
+
+import (
+	"encoding/json"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMessageTypes(t *testing.T) {
+	c.Convey("类型化的消息", t, func() {
+		c.Convey("应该生成正确的消息类型与内容", func() {
+			var msg Message = VideoMessage{MediaID: "mid", Title: "t"}
+			content, err := msg.intoContent()
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.msgType(), c.ShouldEqual, "video")
+			c.So(content, c.ShouldResemble, map[string]interface{}{
+				"media_id": "mid",
+				"title":    "t",
+			})
+		})
+
+		c.Convey("图文消息应该经过校验", func() {
+			_, err := NewsMessage{}.intoContent()
+			c.So(err, c.ShouldEqual, errArticleCountOutOfRange)
+
+			_, err = MPNewsMessage{Articles: []MPNewsArticle{{Title: "t"}}}.intoContent()
+			c.So(err, c.ShouldNotBeNil)
+		})
+	})
+}
+
+func TestAppchatUpdateBody(t *testing.T) {
+	c.Convey("修改群聊会话请求", t, func() {
+		req := reqAppchatUpdate{
+			Update: &AppchatUpdate{
+				ChatID:           "chat",
+				AddMemberUserIDs: []string{"foo", "bar"},
+			},
+		}
+
+		body, err := req.intoBody()
+		c.So(err, c.ShouldBeNil)
+
+		var obj map[string]interface{}
+		c.So(json.Unmarshal(body, &obj), c.ShouldBeNil)
+		c.So(obj, c.ShouldResemble, map[string]interface{}{
+			"chatid":        "chat",
+			"add_user_list": []interface{}{"foo", "bar"},
+		})
+	})
+}
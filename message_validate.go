@@ -0,0 +1,138 @@
+package workwx
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// maxArticlesPerMessage 一条图文消息最多包含的图文数
+	maxArticlesPerMessage = 8
+
+	// 标题、描述等文本字段超长时会被服务端自动截断，只校验不会截断的字段
+	maxArticleURLBytes     = 2048
+	maxArticleAuthorBytes  = 64
+	maxArticleContentBytes = 666 * 1024
+
+	// maxMiniprogramNoticeContentItems 小程序通知消息最多包含的消息内容行数
+	maxMiniprogramNoticeContentItems = 10
+)
+
+var errArticleCountOutOfRange = fmt.Errorf(
+	"an articles message must contain 1 to %d articles",
+	maxArticlesPerMessage,
+)
+
+// validateNewsArticles 校验 news 类型图文消息的各篇图文
+func validateNewsArticles(articles []NewsArticle) error {
+	if len(articles) == 0 || len(articles) > maxArticlesPerMessage {
+		return errArticleCountOutOfRange
+	}
+
+	for i, a := range articles {
+		if err := a.validate(); err != nil {
+			return fmt.Errorf("news article #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *NewsArticle) validate() error {
+	if a.Title == "" {
+		return errors.New("title is required")
+	}
+	if err := checkFieldLen("url", a.URL, maxArticleURLBytes); err != nil {
+		return err
+	}
+	if err := checkFieldLen("picurl", a.PicURL, maxArticleURLBytes); err != nil {
+		return err
+	}
+
+	if (a.AppID == "") != (a.PagePath == "") {
+		return errors.New("appid and pagepath must be set together")
+	}
+	if a.URL == "" && a.AppID == "" {
+		return errors.New("either url or appid/pagepath must be set")
+	}
+
+	return nil
+}
+
+// validateMPNewsArticles 校验 mpnews 类型图文消息的各篇图文
+func validateMPNewsArticles(articles []MPNewsArticle) error {
+	if len(articles) == 0 || len(articles) > maxArticlesPerMessage {
+		return errArticleCountOutOfRange
+	}
+
+	for i, a := range articles {
+		if err := a.validate(); err != nil {
+			return fmt.Errorf("mpnews article #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *MPNewsArticle) validate() error {
+	if a.Title == "" {
+		return errors.New("title is required")
+	}
+	if a.ThumbMediaID == "" {
+		return errors.New("thumb_media_id is required")
+	}
+	if a.Content == "" {
+		return errors.New("content is required")
+	}
+	if err := checkFieldLen("author", a.Author, maxArticleAuthorBytes); err != nil {
+		return err
+	}
+	if err := checkFieldLen("content_source_url", a.ContentSourceURL, maxArticleURLBytes); err != nil {
+		return err
+	}
+	if err := checkFieldLen("content", a.Content, maxArticleContentBytes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func checkFieldLen(name string, value string, maxBytes int) error {
+	if len(value) > maxBytes {
+		return fmt.Errorf("%s is %d bytes, exceeding limit of %d", name, len(value), maxBytes)
+	}
+	return nil
+}
+
+// validateMiniprogramNotice 校验小程序通知消息
+func validateMiniprogramNotice(
+	recipient *Recipient,
+	appID string,
+	title string,
+	contentItems []MiniprogramNoticeContentItem,
+) error {
+	// 小程序通知消息不支持发送到群聊会话
+	if !recipient.isValidForMessageSend() {
+		return errors.New("recipient invalid for miniprogram_notice sending (chatid is not supported)")
+	}
+	if appID == "" {
+		return errors.New("miniprogram_notice: appid is required")
+	}
+	if title == "" {
+		return errors.New("miniprogram_notice: title is required")
+	}
+	if len(contentItems) > maxMiniprogramNoticeContentItems {
+		return fmt.Errorf(
+			"miniprogram_notice: %d content items exceeding limit of %d",
+			len(contentItems),
+			maxMiniprogramNoticeContentItems,
+		)
+	}
+	for i, item := range contentItems {
+		if item.Key == "" {
+			return fmt.Errorf("miniprogram_notice: content item #%d: key is required", i+1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package workwx
+
+import (
+	"strings"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestArticlesValidation(t *testing.T) {
+	c.Convey("图文消息校验逻辑", t, func() {
+		c.Convey("news 类型", func() {
+			validArticle := NewsArticle{Title: "t", URL: "https://example.com"}
+
+			c.Convey("1 到 8 篇合法图文应该通过", func() {
+				c.So(validateNewsArticles([]NewsArticle{validArticle}), c.ShouldBeNil)
+
+				articles := make([]NewsArticle, 8)
+				for i := range articles {
+					articles[i] = validArticle
+				}
+				c.So(validateNewsArticles(articles), c.ShouldBeNil)
+			})
+
+			c.Convey("小程序图文应该通过", func() {
+				a := NewsArticle{Title: "t", AppID: "wx123", PagePath: "index"}
+				c.So(validateNewsArticles([]NewsArticle{a}), c.ShouldBeNil)
+			})
+
+			c.Convey("图文数量超出范围应该报错", func() {
+				c.So(validateNewsArticles(nil), c.ShouldEqual, errArticleCountOutOfRange)
+				c.So(validateNewsArticles(make([]NewsArticle, 9)), c.ShouldEqual, errArticleCountOutOfRange)
+			})
+
+			c.Convey("服务端会自动截断的超长字段应该通过", func() {
+				long := validArticle
+				long.Title = strings.Repeat("x", 129)
+				long.Description = strings.Repeat("x", 513)
+				c.So(validateNewsArticles([]NewsArticle{long}), c.ShouldBeNil)
+			})
+
+			c.Convey("字段不合法应该报错并指明是第几篇", func() {
+				bad := validArticle
+				bad.AppID = "wx123"
+				err := validateNewsArticles([]NewsArticle{validArticle, bad})
+				c.So(err, c.ShouldNotBeNil)
+				c.So(err.Error(), c.ShouldContainSubstring, "#2")
+				c.So(err.Error(), c.ShouldContainSubstring, "pagepath")
+
+				c.So(validateNewsArticles([]NewsArticle{{URL: "https://example.com"}}), c.ShouldNotBeNil)
+				c.So(validateNewsArticles([]NewsArticle{{Title: "t"}}), c.ShouldNotBeNil)
+				c.So(validateNewsArticles([]NewsArticle{{Title: "t", AppID: "wx123"}}), c.ShouldNotBeNil)
+			})
+
+			c.Convey("超长链接应该报错", func() {
+				longURL := "https://example.com/" + strings.Repeat("x", maxArticleURLBytes)
+
+				bad := validArticle
+				bad.URL = longURL
+				err := validateNewsArticles([]NewsArticle{bad})
+				c.So(err, c.ShouldNotBeNil)
+				c.So(err.Error(), c.ShouldContainSubstring, "url")
+
+				bad = validArticle
+				bad.PicURL = longURL
+				err = validateNewsArticles([]NewsArticle{bad})
+				c.So(err, c.ShouldNotBeNil)
+				c.So(err.Error(), c.ShouldContainSubstring, "picurl")
+			})
+		})
+
+		c.Convey("mpnews 类型", func() {
+			validArticle := MPNewsArticle{Title: "t", ThumbMediaID: "mid", Content: "<p>hi</p>"}
+
+			c.Convey("合法图文应该通过", func() {
+				c.So(validateMPNewsArticles([]MPNewsArticle{validArticle, validArticle}), c.ShouldBeNil)
+			})
+
+			c.Convey("缺少 thumb_media_id 应该报错", func() {
+				bad := validArticle
+				bad.ThumbMediaID = ""
+				err := validateMPNewsArticles([]MPNewsArticle{bad})
+				c.So(err, c.ShouldNotBeNil)
+				c.So(err.Error(), c.ShouldContainSubstring, "thumb_media_id")
+			})
+
+			c.Convey("服务端会自动截断的超长字段应该通过", func() {
+				long := validArticle
+				long.Title = strings.Repeat("x", 129)
+				long.Digest = strings.Repeat("x", 513)
+				c.So(validateMPNewsArticles([]MPNewsArticle{long}), c.ShouldBeNil)
+			})
+
+			c.Convey("超长字段应该报错", func() {
+				bad := validArticle
+				bad.Author = strings.Repeat("x", 65)
+				c.So(validateMPNewsArticles([]MPNewsArticle{bad}), c.ShouldNotBeNil)
+
+				bad = validArticle
+				bad.Content = strings.Repeat("x", maxArticleContentBytes+1)
+				c.So(validateMPNewsArticles([]MPNewsArticle{bad}), c.ShouldNotBeNil)
+
+				bad = validArticle
+				bad.ContentSourceURL = "https://example.com/" + strings.Repeat("x", maxArticleURLBytes)
+				err := validateMPNewsArticles([]MPNewsArticle{bad})
+				c.So(err, c.ShouldNotBeNil)
+				c.So(err.Error(), c.ShouldContainSubstring, "content_source_url")
+			})
+		})
+	})
+}
+
+func TestMiniprogramNoticeValidation(t *testing.T) {
+	c.Convey("小程序通知消息校验逻辑", t, func() {
+		recipient := &Recipient{UserIDs: []string{"foo"}}
+		items := []MiniprogramNoticeContentItem{{Key: "订单号", Value: "123"}}
+
+		c.Convey("合法消息应该通过", func() {
+			c.So(validateMiniprogramNotice(recipient, "wx123", "订单更新", items), c.ShouldBeNil)
+		})
+
+		c.Convey("不支持发送到群聊", func() {
+			chat := &Recipient{ChatID: "chat"}
+			c.So(validateMiniprogramNotice(chat, "wx123", "订单更新", items), c.ShouldNotBeNil)
+		})
+
+		c.Convey("缺少 appid 或 title 应该报错", func() {
+			c.So(validateMiniprogramNotice(recipient, "", "订单更新", items), c.ShouldNotBeNil)
+			c.So(validateMiniprogramNotice(recipient, "wx123", "", items), c.ShouldNotBeNil)
+		})
+
+		c.Convey("消息内容超过 10 行应该报错", func() {
+			many := make([]MiniprogramNoticeContentItem, 11)
+			for i := range many {
+				many[i] = items[0]
+			}
+			c.So(validateMiniprogramNotice(recipient, "wx123", "订单更新", many[:10]), c.ShouldBeNil)
+			c.So(validateMiniprogramNotice(recipient, "wx123", "订单更新", many), c.ShouldNotBeNil)
+		})
+	})
+}
@@ -74,6 +74,29 @@ func (x reqJSAPITicket) intoURLValues() url.Values {
 	return url.Values{}
 }
 
+type reqGetCallbackIP struct{}
+
+var _ urlValuer = reqGetCallbackIP{}
+
+func (x reqGetCallbackIP) intoURLValues() url.Values {
+	return url.Values{}
+}
+
+type reqGetAPIDomainIP struct{}
+
+var _ urlValuer = reqGetAPIDomainIP{}
+
+func (x reqGetAPIDomainIP) intoURLValues() url.Values {
+	return url.Values{}
+}
+
+// respIPList 获取企业微信回调IP段、接口IP段的响应
+type respIPList struct {
+	respCommon
+
+	IPList []string `json:"ip_list"`
+}
+
 type respJSAPITicket struct {
 	respCommon
 
@@ -205,6 +228,128 @@ type respUserList struct {
 	Users []*respUserDetail `json:"userlist"`
 }
 
+// userMutation 创建、更新成员请求体
+//
+// 所有可选字段都为指针或 omitempty，未设置的字段不会出现在请求中。
+type userMutation struct {
+	UserID           string               `json:"userid"`
+	Name             *string              `json:"name,omitempty"`
+	Alias            *string              `json:"alias,omitempty"`
+	Mobile           *string              `json:"mobile,omitempty"`
+	DeptIDs          []int64              `json:"department,omitempty"`
+	DeptOrder        []uint32             `json:"order,omitempty"`
+	IsLeaderInDept   []int                `json:"is_leader_in_dept,omitempty"`
+	DirectLeader     *[]string            `json:"direct_leader,omitempty"`
+	MainDepartment   *int64               `json:"main_department,omitempty"`
+	Position         *string              `json:"position,omitempty"`
+	Gender           *string              `json:"gender,omitempty"`
+	Email            *string              `json:"email,omitempty"`
+	Telephone        *string              `json:"telephone,omitempty"`
+	Address          *string              `json:"address,omitempty"`
+	Enable           *int                 `json:"enable,omitempty"`
+	AvatarMediaID    *string              `json:"avatar_mediaid,omitempty"`
+	ExtAttr          *userExtAttrs        `json:"extattr,omitempty"`
+	ToInvite         *bool                `json:"to_invite,omitempty"`
+	ExternalProfile  *userExternalProfile `json:"external_profile,omitempty"`
+	ExternalPosition *string              `json:"external_position,omitempty"`
+}
+
+type userExtAttrs struct {
+	Attrs []UserExtAttr `json:"attrs"`
+}
+
+// userExternalProfile 创建、更新成员请求中的成员对外信息
+//
+// 属性列表为 nil 时不出现在请求中，以免清空已有的属性。
+type userExternalProfile struct {
+	ExternalCorpName string          `json:"external_corp_name"`
+	ExternalAttr     *[]ExternalAttr `json:"external_attr,omitempty"`
+}
+
+// reqUserCreate 创建成员请求
+type reqUserCreate struct {
+	User *UserCreateRequest
+}
+
+var _ bodyer = reqUserCreate{}
+
+func (x reqUserCreate) intoBody() ([]byte, error) {
+	result, err := json.Marshal(x.User.intoUserMutation())
+	if err != nil {
+		// should never happen unless OOM or similar bad things
+		// TODO: error_chain
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// respUserCreate 创建成员响应
+type respUserCreate struct {
+	respCommon
+}
+
+// reqUserUpdate 更新成员请求
+type reqUserUpdate struct {
+	User *UserUpdateRequest
+}
+
+var _ bodyer = reqUserUpdate{}
+
+func (x reqUserUpdate) intoBody() ([]byte, error) {
+	result, err := json.Marshal(x.User.intoUserMutation())
+	if err != nil {
+		// should never happen unless OOM or similar bad things
+		// TODO: error_chain
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// respUserUpdate 更新成员响应
+type respUserUpdate struct {
+	respCommon
+}
+
+// reqUserDelete 删除成员请求
+type reqUserDelete struct {
+	UserID string
+}
+
+var _ urlValuer = reqUserDelete{}
+
+func (x reqUserDelete) intoURLValues() url.Values {
+	return url.Values{
+		"userid": {x.UserID},
+	}
+}
+
+// respUserDelete 删除成员响应
+type respUserDelete struct {
+	respCommon
+}
+
+// reqUserBatchDelete 批量删除成员请求
+type reqUserBatchDelete struct {
+	UserIDs []string `json:"useridlist"`
+}
+
+var _ bodyer = reqUserBatchDelete{}
+
+func (x reqUserBatchDelete) intoBody() ([]byte, error) {
+	body, err := json.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// respUserBatchDelete 批量删除成员响应
+type respUserBatchDelete struct {
+	respCommon
+}
+
 // reqUserIDByMobile 手机号获取 userid 请求
 type reqUserIDByMobile struct {
 	Mobile string `json:"mobile"`
@@ -252,6 +397,72 @@ type respDeptList struct {
 	Department []*DeptInfo `json:"department"`
 }
 
+// reqDeptCreate 创建部门请求
+type reqDeptCreate struct {
+	Dept *DeptCreateRequest
+}
+
+var _ bodyer = reqDeptCreate{}
+
+func (x reqDeptCreate) intoBody() ([]byte, error) {
+	result, err := json.Marshal(x.Dept)
+	if err != nil {
+		// should never happen unless OOM or similar bad things
+		// TODO: error_chain
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// respDeptCreate 创建部门响应
+type respDeptCreate struct {
+	respCommon
+
+	ID int64 `json:"id"`
+}
+
+// reqDeptUpdate 更新部门请求
+type reqDeptUpdate struct {
+	Update *DeptUpdateRequest
+}
+
+var _ bodyer = reqDeptUpdate{}
+
+func (x reqDeptUpdate) intoBody() ([]byte, error) {
+	result, err := json.Marshal(x.Update)
+	if err != nil {
+		// should never happen unless OOM or similar bad things
+		// TODO: error_chain
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// respDeptUpdate 更新部门响应
+type respDeptUpdate struct {
+	respCommon
+}
+
+// reqDeptDelete 删除部门请求
+type reqDeptDelete struct {
+	ID int64
+}
+
+var _ urlValuer = reqDeptDelete{}
+
+func (x reqDeptDelete) intoURLValues() url.Values {
+	return url.Values{
+		"id": {strconv.FormatInt(x.ID, 10)},
+	}
+}
+
+// respDeptDelete 删除部门响应
+type respDeptDelete struct {
+	respCommon
+}
+
 // reqAppchatGet 获取群聊会话请求
 type reqAppchatGet struct {
 	ChatID string
@@ -297,6 +508,29 @@ type respAppchatCreate struct {
 	ChatID string `json:"chatid"`
 }
 
+// reqAppchatUpdate 修改群聊会话请求
+type reqAppchatUpdate struct {
+	Update *AppchatUpdate
+}
+
+var _ bodyer = reqAppchatUpdate{}
+
+func (x reqAppchatUpdate) intoBody() ([]byte, error) {
+	result, err := json.Marshal(x.Update)
+	if err != nil {
+		// should never happen unless OOM or similar bad things
+		// TODO: error_chain
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// respAppchatUpdate 修改群聊会话响应
+type respAppchatUpdate struct {
+	respCommon
+}
+
 // reqMediaUpload 临时素材上传请求
 type reqMediaUpload struct {
 	Type  string
@@ -992,3 +1226,107 @@ type TaskCardBtn struct {
 	// IsBold 按钮字体是否加粗，默认false
 	IsBold bool `json:"is_bold"`
 }
+
+// NewsArticle 图文消息中的一篇图文
+type NewsArticle struct {
+	// Title 标题，不超过128个字节，超过会自动截断
+	Title string `json:"title"`
+	// Description 描述，不超过512个字节，超过会自动截断
+	Description string `json:"description,omitempty"`
+	// URL 点击后跳转的链接。
+	URL string `json:"url,omitempty"`
+	// PicURL 图文消息的图片链接，支持JPG、PNG格式，较好的效果为大图 1068*455，小图150*150。
+	PicURL string `json:"picurl,omitempty"`
+	// AppID 小程序appid，必须是与当前应用关联的小程序，appid和pagepath必须同时填写，填写后会忽略url字段
+	//
+	// 仅应用消息支持，群机器人消息不支持。
+	AppID string `json:"appid,omitempty"`
+	// PagePath 点击消息卡片后的小程序页面，仅限本小程序内的页面。appid和pagepath必须同时填写，填写后会忽略url字段
+	PagePath string `json:"pagepath,omitempty"`
+}
+
+// MPNewsArticle mpnews 类型图文消息中的一篇图文
+//
+// mpnews 类型的图文消息，跟普通的图文消息一致，唯一的差异是图文内容存储在企业微信。
+type MPNewsArticle struct {
+	// Title 标题，不超过128个字节，超过会自动截断
+	Title string `json:"title"`
+	// ThumbMediaID 图文消息缩略图的media_id, 可以通过素材管理接口获得。此处thumb_media_id即上传接口返回的media_id
+	ThumbMediaID string `json:"thumb_media_id"`
+	// Author 图文消息的作者，不超过64个字节
+	Author string `json:"author,omitempty"`
+	// ContentSourceURL 图文消息点击“阅读原文”之后的页面链接
+	ContentSourceURL string `json:"content_source_url,omitempty"`
+	// Content 图文消息的内容，支持html标签，不超过666 K个字节
+	Content string `json:"content"`
+	// Digest 图文消息的描述，不超过512个字节，超过会自动截断
+	Digest string `json:"digest,omitempty"`
+}
+
+// MiniprogramNoticeContentItem 小程序通知消息中的一行消息内容
+type MiniprogramNoticeContentItem struct {
+	// Key 长度10个汉字以内
+	Key string `json:"key"`
+	// Value 长度30个汉字以内（支持id转译）
+	Value string `json:"value"`
+}
+
+// reqWebhookSend 群机器人发送消息请求
+type reqWebhookSend struct {
+	Key     string
+	MsgType string
+	Content interface{}
+}
+
+var _ urlValuer = reqWebhookSend{}
+var _ bodyer = reqWebhookSend{}
+
+func (x reqWebhookSend) intoURLValues() url.Values {
+	return url.Values{
+		"key": {x.Key},
+	}
+}
+
+func (x reqWebhookSend) intoBody() ([]byte, error) {
+	obj := map[string]interface{}{
+		"msgtype": x.MsgType,
+	}
+
+	// msgtype polymorphism
+	obj[x.MsgType] = x.Content
+
+	result, err := json.Marshal(obj)
+	if err != nil {
+		// should never happen unless OOM or similar bad things
+		// TODO: error_chain
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// respWebhookSend 群机器人发送消息响应
+type respWebhookSend struct {
+	respCommon
+}
+
+// reqWebhookUploadMedia 群机器人上传文件请求
+type reqWebhookUploadMedia struct {
+	Key   string
+	Type  string
+	Media *Media
+}
+
+var _ urlValuer = reqWebhookUploadMedia{}
+var _ mediaUploader = reqWebhookUploadMedia{}
+
+func (x reqWebhookUploadMedia) intoURLValues() url.Values {
+	return url.Values{
+		"key":  {x.Key},
+		"type": {x.Type},
+	}
+}
+
+func (x reqWebhookUploadMedia) getMedia() *Media {
+	return x.Media
+}
@@ -1,9 +1,9 @@
 package workwx
 
 import (
+	"context"
 	"net/http"
-
-	"github.com/gin-gonic/gin"
+	"time"
 
 	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
 	"github.com/xen0n/go-workwx/internal/lowlevel/httpapi"
@@ -12,7 +12,23 @@ import (
 // RxMessageHandler 用来接收消息的接口。
 type RxMessageHandler interface {
 	// OnIncomingMessage 一条消息到来时的回调。
-	OnIncomingMessage(ctx *gin.Context, msg *RxMessage) error
+	//
+	// ctx 为本次回调请求的 context，可用 HTTPRequestFromContext 取出原始 HTTP 请求。
+	// 经 NewRxAsyncHandler 异步处理时，ctx 中不再带有原始 HTTP 请求等仅在请求期间有效的值。
+	//
+	// 如需被动回复，返回相应的 RxReply 即可，SDK 会负责加密、签名并作为响应发出；
+	// 不需要被动回复时返回 nil。
+	OnIncomingMessage(ctx context.Context, msg *RxMessage) (RxReply, error)
+}
+
+// RxMessageHandlerFunc 将普通函数适配为 RxMessageHandler
+type RxMessageHandlerFunc func(ctx context.Context, msg *RxMessage) (RxReply, error)
+
+var _ RxMessageHandler = RxMessageHandlerFunc(nil)
+
+// OnIncomingMessage 一条消息到来时的回调。
+func (f RxMessageHandlerFunc) OnIncomingMessage(ctx context.Context, msg *RxMessage) (RxReply, error) {
+	return f(ctx, msg)
 }
 
 type lowlevelEnvelopeHandler struct {
@@ -21,47 +37,114 @@ type lowlevelEnvelopeHandler struct {
 
 var _ httpapi.EnvelopeHandler = (*lowlevelEnvelopeHandler)(nil)
 
-func (h *lowlevelEnvelopeHandler) OnIncomingEnvelope(ctx *gin.Context, rx envelope.Envelope) error {
+func (h *lowlevelEnvelopeHandler) OnIncomingEnvelope(ctx context.Context, rx envelope.Envelope) ([]byte, error) {
 	msg, err := fromEnvelope(rx.Msg)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return h.highlevelHandler.OnIncomingMessage(ctx, msg)
+
+	reply, err := h.highlevelHandler.OnIncomingMessage(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, nil
+	}
+
+	return reply.intoReplyXML(msg, time.Now())
 }
 
+// HTTPHandler 企业微信回调请求的 net/http 处理器
+//
+// 本类型实现了 http.Handler，可直接挂载到 net/http 的路由上；gin 用户可使用
+// workwxgin 包提供的适配器。本类型不保存任何请求相关的状态，可被并发使用。
 type HTTPHandler struct {
-	ctx   *gin.Context
-	inner *httpapi.LowLevelHandler
+	inner       *httpapi.LowLevelHandler
+	ipAllowlist *CallbackIPAllowlist
 }
 
 var _ http.Handler = (*HTTPHandler)(nil)
 
+// NewHTTPHandler 构造一个回调请求处理器
+//
+// 可通过 opts 开启防重放等功能，详见各 HTTPHandlerOption。
 func NewHTTPHandler(
 	token string,
 	encodingAESKey string,
 	rxMessageHandler RxMessageHandler,
+	opts ...HTTPHandlerOption,
 ) (*HTTPHandler, error) {
+	var options httpHandlerOptions
+	for _, o := range opts {
+		o.applyTo(&options)
+	}
+
 	lleh := &lowlevelEnvelopeHandler{
 		highlevelHandler: rxMessageHandler,
 	}
 
-	llHandler, err := httpapi.NewLowLevelHandler(token, encodingAESKey, lleh)
+	llHandler, err := httpapi.NewLowLevelHandler(
+		token,
+		encodingAESKey,
+		lleh,
+		options.envelopeOpts...,
+	)
 	if err != nil {
 		return nil, err
 	}
 
 	obj := HTTPHandler{
-		inner: llHandler,
+		inner:       llHandler,
+		ipAllowlist: options.ipAllowlist,
 	}
 
 	return &obj, nil
 }
 
-func (h *HTTPHandler) SetGinContext(c *gin.Context) {
-	h.ctx = c
-	h.inner.SetGinContext(h.ctx)
+// ServeHTTP 处理一个回调请求
+func (h *HTTPHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if h.ipAllowlist != nil && !h.ipAllowlist.AllowsRequest(r) {
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	ctx := WithRxRequestScopedValue(r.Context(), httpRequestCtxKey{}, r)
+	h.inner.ServeHTTP(rw, r.WithContext(ctx))
 }
 
-func (h *HTTPHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	h.inner.ServeHTTP(rw, r)
+type httpRequestCtxKey struct{}
+
+// HTTPRequestFromContext 取出回调处理时 context 所属的原始 HTTP 请求
+//
+// 可用于获取请求来源地址、请求头等元数据；请求体已被 SDK 读取，不可再次读取。
+// 如 ctx 并非来自 HTTPHandler，或处于异步处理器中（此时请求已经结束），则返回 nil, false。
+func HTTPRequestFromContext(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(httpRequestCtxKey{}).(*http.Request)
+	return r, ok
+}
+
+type rxRequestScopedKeysCtxKey struct{}
+
+// WithRxRequestScopedValue 向 ctx 中放入仅在回调请求处理期间有效的值
+//
+// 与 context.WithValue 相同，但异步处理器（NewRxAsyncHandler）交给后台处理的 context
+// 中不会带有该值，以免在请求结束后访问已被回收复用的请求状态。key 必须是可比较的。
+func WithRxRequestScopedValue(ctx context.Context, key interface{}, val interface{}) context.Context {
+	keys, _ := ctx.Value(rxRequestScopedKeysCtxKey{}).([]interface{})
+	newKeys := make([]interface{}, len(keys), len(keys)+1)
+	copy(newKeys, keys)
+	newKeys = append(newKeys, key)
+
+	ctx = context.WithValue(ctx, rxRequestScopedKeysCtxKey{}, newKeys)
+	return context.WithValue(ctx, key, val)
+}
+
+func isRxRequestScopedKey(ctx context.Context, key interface{}) bool {
+	keys, _ := ctx.Value(rxRequestScopedKeysCtxKey{}).([]interface{})
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,310 @@
+package workwx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RxOverflowPolicy 异步回调处理队列已满时的处理策略
+type RxOverflowPolicy int
+
+const (
+	// RxOverflowBlock 阻塞等待队列空出位置，直到回调请求本身被取消
+	RxOverflowBlock RxOverflowPolicy = iota
+	// RxOverflowDrop 丢弃消息（并报告给错误处理函数），仍以 200 确认回调
+	RxOverflowDrop
+	// RxOverflowReject 拒绝消息，以 503 响应回调，由企业微信稍后重试
+	RxOverflowReject
+)
+
+// rxStatusError 带有 HTTP 状态码的错误，HTTPHandler 会以相应状态码响应
+type rxStatusError struct {
+	msg        string
+	statusCode int
+}
+
+func (e *rxStatusError) Error() string {
+	return e.msg
+}
+
+func (e *rxStatusError) HTTPStatusCode() int {
+	return e.statusCode
+}
+
+// ErrRxQueueFull 异步回调处理队列已满
+//
+// 在 RxOverflowReject 策略下由 OnIncomingMessage 返回，HTTPHandler 会以 503 响应；
+// 在 RxOverflowDrop 策略下则报告给错误处理函数。
+var ErrRxQueueFull error = &rxStatusError{
+	msg:        "async rx handler queue is full",
+	statusCode: http.StatusServiceUnavailable,
+}
+
+// ErrRxHandlerClosed 异步回调处理器已关闭，HTTPHandler 会以 503 响应
+var ErrRxHandlerClosed error = &rxStatusError{
+	msg:        "async rx handler is shut down",
+	statusCode: http.StatusServiceUnavailable,
+}
+
+type rxAsyncOptions struct {
+	queueSize      int
+	workers        int
+	overflowPolicy RxOverflowPolicy
+	onError        func(msg *RxMessage, err error)
+}
+
+// RxAsyncOption 异步回调处理器构造参数
+type RxAsyncOption interface {
+	applyTo(*rxAsyncOptions)
+}
+
+func defaultRxAsyncOptions() rxAsyncOptions {
+	return rxAsyncOptions{
+		queueSize:      1024,
+		workers:        4,
+		overflowPolicy: RxOverflowBlock,
+		onError: func(msg *RxMessage, err error) {
+			logrus.Errorf("async rx handler failed: msg=%s err=%+v", msg, err)
+		},
+	}
+}
+
+type withRxAsyncQueueSize struct {
+	x int
+}
+
+// WithRxAsyncQueueSize 设置队列长度，默认 1024
+func WithRxAsyncQueueSize(n int) RxAsyncOption {
+	return &withRxAsyncQueueSize{x: n}
+}
+
+func (x *withRxAsyncQueueSize) applyTo(y *rxAsyncOptions) {
+	y.queueSize = x.x
+}
+
+type withRxAsyncWorkers struct {
+	x int
+}
+
+// WithRxAsyncWorkers 设置并发处理消息的 worker 数，默认 4
+func WithRxAsyncWorkers(n int) RxAsyncOption {
+	return &withRxAsyncWorkers{x: n}
+}
+
+func (x *withRxAsyncWorkers) applyTo(y *rxAsyncOptions) {
+	y.workers = x.x
+}
+
+type withRxAsyncOverflowPolicy struct {
+	x RxOverflowPolicy
+}
+
+// WithRxAsyncOverflowPolicy 设置队列已满时的处理策略，默认 RxOverflowBlock
+func WithRxAsyncOverflowPolicy(p RxOverflowPolicy) RxAsyncOption {
+	return &withRxAsyncOverflowPolicy{x: p}
+}
+
+func (x *withRxAsyncOverflowPolicy) applyTo(y *rxAsyncOptions) {
+	y.overflowPolicy = x.x
+}
+
+type withRxAsyncErrorHandler struct {
+	x func(msg *RxMessage, err error)
+}
+
+// WithRxAsyncErrorHandler 设置错误处理函数
+//
+// 消息处理出错、panic 或被丢弃时都会调用该函数；默认以 logrus 记录错误日志。
+// 该函数可能被多个 worker 并发调用。
+func WithRxAsyncErrorHandler(f func(msg *RxMessage, err error)) RxAsyncOption {
+	return &withRxAsyncErrorHandler{x: f}
+}
+
+func (x *withRxAsyncErrorHandler) applyTo(y *rxAsyncOptions) {
+	y.onError = x.x
+}
+
+//
+//
+//
+
+type rxAsyncItem struct {
+	ctx context.Context
+	msg *RxMessage
+}
+
+// RxAsyncHandler 异步回调处理器
+//
+// 回调消息被放入有界队列后立即以 200 确认，由后台 worker 调用内层处理器处理，
+// 避免处理缓慢导致企业微信超时重试。由于响应先于处理发出，内层处理器返回的
+// 被动回复会被忽略。
+type RxAsyncHandler struct {
+	inner RxMessageHandler
+	opts  rxAsyncOptions
+
+	queue   chan rxAsyncItem
+	stopCh  chan struct{}
+	workers sync.WaitGroup
+
+	mu      sync.RWMutex
+	closed  bool
+	pending sync.WaitGroup
+}
+
+var _ RxMessageHandler = (*RxAsyncHandler)(nil)
+
+// NewRxAsyncHandler 构造一个异步回调处理器，并启动 worker
+//
+// 不再使用时，应调用 Shutdown 以处理完队列中剩余的消息。
+func NewRxAsyncHandler(inner RxMessageHandler, opts ...RxAsyncOption) *RxAsyncHandler {
+	optionsObj := defaultRxAsyncOptions()
+	for _, o := range opts {
+		o.applyTo(&optionsObj)
+	}
+	if optionsObj.queueSize < 0 {
+		optionsObj.queueSize = 0
+	}
+	if optionsObj.workers <= 0 {
+		optionsObj.workers = 1
+	}
+
+	h := &RxAsyncHandler{
+		inner:  inner,
+		opts:   optionsObj,
+		queue:  make(chan rxAsyncItem, optionsObj.queueSize),
+		stopCh: make(chan struct{}),
+	}
+
+	h.workers.Add(optionsObj.workers)
+	for i := 0; i < optionsObj.workers; i++ {
+		go h.worker()
+	}
+
+	return h
+}
+
+// OnIncomingMessage 将消息放入队列
+func (h *RxAsyncHandler) OnIncomingMessage(ctx context.Context, msg *RxMessage) (RxReply, error) {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return nil, ErrRxHandlerClosed
+	}
+	h.pending.Add(1)
+	h.mu.RUnlock()
+	defer h.pending.Done()
+
+	item := rxAsyncItem{
+		// 回调请求结束后 ctx 即被取消，处理时不应受其影响
+		ctx: detachedContext{ctx},
+		msg: msg,
+	}
+
+	select {
+	case h.queue <- item:
+		return nil, nil
+	default:
+	}
+
+	switch h.opts.overflowPolicy {
+	case RxOverflowDrop:
+		h.opts.onError(msg, ErrRxQueueFull)
+		return nil, nil
+
+	case RxOverflowReject:
+		return nil, ErrRxQueueFull
+
+	default:
+		select {
+		case h.queue <- item:
+			return nil, nil
+		case <-h.stopCh:
+			return nil, ErrRxHandlerClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (h *RxAsyncHandler) worker() {
+	defer h.workers.Done()
+
+	for item := range h.queue {
+		h.process(item)
+	}
+}
+
+func (h *RxAsyncHandler) process(item rxAsyncItem) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.opts.onError(item.msg, fmt.Errorf("panic in async rx handler: %v", r))
+		}
+	}()
+
+	_, err := h.inner.OnIncomingMessage(item.ctx, item.msg)
+	if err != nil {
+		h.opts.onError(item.msg, err)
+	}
+}
+
+// Shutdown 停止接收新消息，并等待队列中已有的消息处理完毕
+//
+// 之后到来的回调会以 503 响应。ctx 被取消时不再等待，返回 ctx.Err()，但剩余的
+// 消息仍会在后台继续处理。
+func (h *RxAsyncHandler) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return h.wait(ctx)
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	// 唤醒阻塞中的入队操作，待其全部返回后再关闭队列
+	close(h.stopCh)
+	h.pending.Wait()
+	close(h.queue)
+
+	return h.wait(ctx)
+}
+
+func (h *RxAsyncHandler) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// detachedContext 保留父 context 中的值，但不继承其取消与超时
+//
+// 通过 WithRxRequestScopedValue 放入的值（如原始 HTTP 请求、gin 的 *gin.Context）
+// 在请求结束后即失效，因此不会被保留。
+type detachedContext struct {
+	parent context.Context
+}
+
+var _ context.Context = detachedContext{}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	if isRxRequestScopedKey(c.parent, key) {
+		return nil
+	}
+	return c.parent.Value(key)
+}
@@ -0,0 +1,193 @@
+package workwx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxAsyncHandler(t *testing.T) {
+	c.Convey("异步处理回调消息", t, func() {
+		ctx := context.Background()
+		msg := &RxMessage{MsgType: MessageTypeText, MsgID: 1, FromUserID: "foo"}
+
+		var mu sync.Mutex
+		var errs []error
+		onError := WithRxAsyncErrorHandler(func(_ *RxMessage, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		})
+
+		c.Convey("消息应该被后台处理，且 Shutdown 会处理完剩余消息", func() {
+			var processed int
+			release := make(chan struct{})
+			inner := RxMessageHandlerFunc(func(_ context.Context, _ *RxMessage) (RxReply, error) {
+				<-release
+				mu.Lock()
+				defer mu.Unlock()
+				processed++
+				return TextReply{Content: "ignored"}, nil
+			})
+			h := NewRxAsyncHandler(inner, WithRxAsyncWorkers(2), onError)
+
+			for i := 0; i < 5; i++ {
+				reply, err := h.OnIncomingMessage(ctx, msg)
+				c.So(err, c.ShouldBeNil)
+				c.So(reply, c.ShouldBeNil)
+			}
+
+			close(release)
+			c.So(h.Shutdown(ctx), c.ShouldBeNil)
+			c.So(processed, c.ShouldEqual, 5)
+			c.So(errs, c.ShouldBeEmpty)
+
+			_, err := h.OnIncomingMessage(ctx, msg)
+			c.So(err, c.ShouldEqual, ErrRxHandlerClosed)
+		})
+
+		c.Convey("处理时的 context 不应随回调请求取消，也不应带有请求期间的值", func() {
+			type traceKey struct{}
+
+			var gotErr error
+			var gotReq bool
+			var gotTrace interface{}
+			inner := RxMessageHandlerFunc(func(ctx context.Context, _ *RxMessage) (RxReply, error) {
+				gotErr = ctx.Err()
+				_, gotReq = HTTPRequestFromContext(ctx)
+				gotTrace = ctx.Value(traceKey{})
+				return nil, nil
+			})
+			h := NewRxAsyncHandler(inner, onError)
+
+			reqCtx := context.WithValue(ctx, traceKey{}, "trace")
+			reqCtx = WithRxRequestScopedValue(reqCtx, httpRequestCtxKey{}, httptest.NewRequest(http.MethodPost, "/cb", nil))
+			_, ok := HTTPRequestFromContext(reqCtx)
+			c.So(ok, c.ShouldBeTrue)
+
+			reqCtx, cancel := context.WithCancel(reqCtx)
+			_, err := h.OnIncomingMessage(reqCtx, msg)
+			c.So(err, c.ShouldBeNil)
+			cancel()
+
+			c.So(h.Shutdown(ctx), c.ShouldBeNil)
+			c.So(gotErr, c.ShouldBeNil)
+			c.So(gotReq, c.ShouldBeFalse)
+			c.So(gotTrace, c.ShouldEqual, "trace")
+		})
+
+		c.Convey("处理出错或 panic 时应该报告给错误处理函数", func() {
+			inner := RxMessageHandlerFunc(func(_ context.Context, msg *RxMessage) (RxReply, error) {
+				if msg.MsgID == 1 {
+					return nil, errors.New("boom")
+				}
+				panic("oops")
+			})
+			h := NewRxAsyncHandler(inner, WithRxAsyncWorkers(1), onError)
+
+			_, _ = h.OnIncomingMessage(ctx, msg)
+			_, _ = h.OnIncomingMessage(ctx, &RxMessage{MsgID: 2})
+			c.So(h.Shutdown(ctx), c.ShouldBeNil)
+
+			c.So(errs, c.ShouldHaveLength, 2)
+			c.So(errs[0].Error(), c.ShouldEqual, "boom")
+			c.So(errs[1].Error(), c.ShouldContainSubstring, "oops")
+		})
+
+		c.Convey("队列已满时", func() {
+			release := make(chan struct{})
+			started := make(chan struct{}, 1)
+			inner := RxMessageHandlerFunc(func(_ context.Context, _ *RxMessage) (RxReply, error) {
+				started <- struct{}{}
+				<-release
+				return nil, nil
+			})
+
+			// 占满唯一的 worker 与长度为 1 的队列
+			fill := func(h *RxAsyncHandler) {
+				_, err := h.OnIncomingMessage(ctx, msg)
+				c.So(err, c.ShouldBeNil)
+				<-started
+				_, err = h.OnIncomingMessage(ctx, msg)
+				c.So(err, c.ShouldBeNil)
+			}
+
+			c.Convey("RxOverflowReject 应该返回 503 错误", func() {
+				h := NewRxAsyncHandler(
+					inner,
+					WithRxAsyncWorkers(1),
+					WithRxAsyncQueueSize(1),
+					WithRxAsyncOverflowPolicy(RxOverflowReject),
+					onError,
+				)
+				fill(h)
+
+				_, err := h.OnIncomingMessage(ctx, msg)
+				c.So(err, c.ShouldEqual, ErrRxQueueFull)
+				c.So(err.(interface{ HTTPStatusCode() int }).HTTPStatusCode(), c.ShouldEqual, 503)
+
+				close(release)
+				c.So(h.Shutdown(ctx), c.ShouldBeNil)
+			})
+
+			c.Convey("RxOverflowDrop 应该丢弃消息并报告", func() {
+				h := NewRxAsyncHandler(
+					inner,
+					WithRxAsyncWorkers(1),
+					WithRxAsyncQueueSize(1),
+					WithRxAsyncOverflowPolicy(RxOverflowDrop),
+					onError,
+				)
+				fill(h)
+
+				_, err := h.OnIncomingMessage(ctx, msg)
+				c.So(err, c.ShouldBeNil)
+				c.So(errs, c.ShouldResemble, []error{ErrRxQueueFull})
+
+				close(release)
+				c.So(h.Shutdown(ctx), c.ShouldBeNil)
+			})
+
+			c.Convey("RxOverflowBlock 应该阻塞至请求取消", func() {
+				h := NewRxAsyncHandler(
+					inner,
+					WithRxAsyncWorkers(1),
+					WithRxAsyncQueueSize(1),
+					onError,
+				)
+				fill(h)
+
+				reqCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+				defer cancel()
+				_, err := h.OnIncomingMessage(reqCtx, msg)
+				c.So(errors.Is(err, context.DeadlineExceeded), c.ShouldBeTrue)
+
+				close(release)
+				c.So(h.Shutdown(ctx), c.ShouldBeNil)
+			})
+		})
+
+		c.Convey("Shutdown 超时应该返回 ctx 的错误", func() {
+			release := make(chan struct{})
+			inner := RxMessageHandlerFunc(func(_ context.Context, _ *RxMessage) (RxReply, error) {
+				<-release
+				return nil, nil
+			})
+			h := NewRxAsyncHandler(inner, onError)
+			_, _ = h.OnIncomingMessage(ctx, msg)
+
+			shutdownCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			c.So(errors.Is(h.Shutdown(shutdownCtx), context.DeadlineExceeded), c.ShouldBeTrue)
+
+			close(release)
+			c.So(h.Shutdown(ctx), c.ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,116 @@
+package workwx
+
+import (
+	"context"
+	"crypto/sha1" //nolint: gosec  // only used for hashing dedupe keys
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
+)
+
+// RxDedupeStore 回调消息排重所用的存储
+//
+// 可自行实现（如基于 Redis）以在多个进程间共享。
+type RxDedupeStore interface {
+	// MarkIfAbsent 记录 key 并保留 ttl 时长，返回调用前该 key 是否不存在（或已过期）
+	MarkIfAbsent(key string, ttl time.Duration) (bool, error)
+	// Forget 删除 key 的记录
+	Forget(key string) error
+}
+
+// NewMemoryRxDedupeStore 构造一个最多容纳 capacity 条记录的内存排重存储
+//
+// 存储已满时，最早记录的 key 会被优先淘汰。
+func NewMemoryRxDedupeStore(capacity int) RxDedupeStore {
+	return envelope.NewMemoryNonceCache(capacity)
+}
+
+// DefaultRxDedupeTTL 回调消息排重记录的默认保留时长
+//
+// 企业微信在 5 秒内收不到响应会断开连接并重试，共重试 3 次，因此几分钟足矣。
+const DefaultRxDedupeTTL = 5 * time.Minute
+
+// rxDedupeHandler 为 RxMessageHandler 提供排重
+type rxDedupeHandler struct {
+	inner RxMessageHandler
+	store RxDedupeStore
+	ttl   time.Duration
+}
+
+var _ RxMessageHandler = (*rxDedupeHandler)(nil)
+
+// NewRxDedupeHandler 为 RxMessageHandler 包装一层排重逻辑
+//
+// 企业微信在回调超时时会重试，导致同一条消息或事件被多次投递。本包装对消息按
+// MsgID、对事件按 FromUserName + CreateTime + Event + ChangeType 以及完整的明文 XML
+// 排重（同一秒内可能有多个来自 sys 的通讯录变更事件，仅凭前几项无法区分），
+// 重复的投递直接以空响应确认，不再调用 inner（因此也不会有被动回复）。
+// inner 处理出错时会删除排重记录，以便重试时重新处理。ttl 不大于 0 时使用
+// DefaultRxDedupeTTL。
+//
+// 注意：排重记录在开始处理时即写入。如重试在首次投递仍在处理时到达，重试会被
+// 当作重复投递确认；此后首次投递若处理失败，排重记录虽被删除，但企业微信已收到
+// 确认、不会再重试，消息即丢失。inner 耗时可能接近企业微信的 5 秒超时时，
+// 请配合 NewRxAsyncHandler 等方式尽快响应，或自行实现可靠的重新投递。
+func NewRxDedupeHandler(
+	inner RxMessageHandler,
+	store RxDedupeStore,
+	ttl time.Duration,
+) RxMessageHandler {
+	if ttl <= 0 {
+		ttl = DefaultRxDedupeTTL
+	}
+
+	return &rxDedupeHandler{
+		inner: inner,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// OnIncomingMessage 一条消息到来时的回调。
+func (h *rxDedupeHandler) OnIncomingMessage(ctx context.Context, msg *RxMessage) (RxReply, error) {
+	key := rxDedupeKey(msg)
+
+	fresh, err := h.store.MarkIfAbsent(key, h.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		// 重复投递，直接确认
+		return nil, nil
+	}
+
+	reply, err := h.inner.OnIncomingMessage(ctx, msg)
+	if err != nil {
+		// 允许企业微信重试时重新处理
+		_ = h.store.Forget(key)
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// rxDedupeKey 计算消息的排重 key
+func rxDedupeKey(msg *RxMessage) string {
+	if msg.MsgType != MessageTypeEvent && msg.MsgID != 0 {
+		return "msg:" + strconv.FormatInt(msg.MsgID, 10)
+	}
+
+	// 重试时明文 XML 与首次投递一致，而不同事件（如变更不同成员）至少
+	// UserID、ExternalUserID 等字段会有差别
+	//nolint: gosec  // only used for hashing dedupe keys
+	h := sha1.New()
+	fmt.Fprintf(
+		h,
+		"%s|%d|%s|%s|",
+		msg.FromUserID,
+		msg.SendTime.Unix(),
+		msg.Event,
+		msg.ChangeType,
+	)
+	_, _ = h.Write(msg.RawXML())
+	return fmt.Sprintf("event:%x", h.Sum(nil))
+}
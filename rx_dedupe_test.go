@@ -0,0 +1,91 @@
+package workwx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxDedupeHandler(t *testing.T) {
+	c.Convey("回调消息排重", t, func() {
+		calls := 0
+		var failNext bool
+		inner := RxMessageHandlerFunc(func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+			calls++
+			if failNext {
+				failNext = false
+				return nil, errors.New("boom")
+			}
+			return TextReply{Content: "ok"}, nil
+		})
+		h := NewRxDedupeHandler(inner, NewMemoryRxDedupeStore(128), 0)
+		ctx := context.Background()
+
+		textMsg := &RxMessage{MsgType: MessageTypeText, MsgID: 2018405441, FromUserID: "foo"}
+
+		c.Convey("重复投递的消息不应该再次处理", func() {
+			reply, err := h.OnIncomingMessage(ctx, textMsg)
+			c.So(err, c.ShouldBeNil)
+			c.So(reply, c.ShouldResemble, TextReply{Content: "ok"})
+
+			reply, err = h.OnIncomingMessage(ctx, textMsg)
+			c.So(err, c.ShouldBeNil)
+			c.So(reply, c.ShouldBeNil)
+			c.So(calls, c.ShouldEqual, 1)
+		})
+
+		c.Convey("处理出错的消息在重试时应该重新处理", func() {
+			failNext = true
+			_, err := h.OnIncomingMessage(ctx, textMsg)
+			c.So(err, c.ShouldNotBeNil)
+
+			reply, err := h.OnIncomingMessage(ctx, textMsg)
+			c.So(err, c.ShouldBeNil)
+			c.So(reply, c.ShouldNotBeNil)
+			c.So(calls, c.ShouldEqual, 2)
+		})
+
+		c.Convey("事件按发送者、时间与事件类型排重", func() {
+			sendTime := time.Unix(1403610513, 0)
+			ev := &RxMessage{
+				MsgType:    MessageTypeEvent,
+				FromUserID: "sys",
+				SendTime:   sendTime,
+				Event:      EventTypeChangeExternalContact,
+			}
+			other := *ev
+			other.SendTime = sendTime.Add(time.Second)
+
+			_, _ = h.OnIncomingMessage(ctx, ev)
+			_, _ = h.OnIncomingMessage(ctx, ev)
+			_, _ = h.OnIncomingMessage(ctx, &other)
+			c.So(calls, c.ShouldEqual, 2)
+
+			c.So(rxDedupeKey(ev), c.ShouldStartWith, "event:")
+			c.So(rxDedupeKey(textMsg), c.ShouldEqual, "msg:2018405441")
+		})
+
+		c.Convey("同一秒内来自 sys 的不同通讯录变更事件不应被当作重复", func() {
+			parse := func(body string) *RxMessage {
+				msg, err := fromEnvelope([]byte(body))
+				c.So(err, c.ShouldBeNil)
+				return msg
+			}
+			createUser := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>create_user</ChangeType><UserID><![CDATA[zhangsan]]></UserID><Name><![CDATA[张三]]></Name></xml>")
+			updateUser := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>update_user</ChangeType><UserID><![CDATA[zhangsan]]></UserID><Name><![CDATA[张三]]></Name></xml>")
+			updateOther := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>update_user</ChangeType><UserID><![CDATA[lisi]]></UserID><Name><![CDATA[李四]]></Name></xml>")
+
+			_, _ = h.OnIncomingMessage(ctx, createUser)
+			_, _ = h.OnIncomingMessage(ctx, updateUser)
+			_, _ = h.OnIncomingMessage(ctx, updateOther)
+			c.So(calls, c.ShouldEqual, 3)
+
+			// a retry of any of them is still a duplicate
+			_, _ = h.OnIncomingMessage(ctx, parse(string(updateOther.RawXML())))
+			c.So(calls, c.ShouldEqual, 3)
+		})
+	})
+}
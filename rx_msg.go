@@ -1,6 +1,7 @@
 package workwx
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"strings"
@@ -21,6 +22,15 @@ type RxMessage struct {
 	EventKey   string      // 事件位置
 	ChangeType ChangeType  // ChangeType 变更类型 Event为change_external_contact存在
 	extras     messageKind
+	rawXML     []byte
+}
+
+// ParseRxMessage 解析一条解密后的明文 XML 回调消息
+//
+// 供自行处理回调请求的场景使用，如以 callbackcrypto 包解密后解析；使用
+// HTTPHandler 时 SDK 会自动完成这一步。
+func ParseRxMessage(body []byte) (*RxMessage, error) {
+	return fromEnvelope(body)
 }
 
 func fromEnvelope(body []byte) (*RxMessage, error) {
@@ -55,6 +65,7 @@ func fromEnvelope(body []byte) (*RxMessage, error) {
 			EventKey:   common.EventKey,
 			ChangeType: common.ChangeType,
 			extras:     extras,
+			rawXML:     body,
 		}
 	}
 	return &obj, nil
@@ -76,13 +87,64 @@ func (m *RxMessage) String() string {
 		m.ChangeType,
 	)
 
-	m.extras.formatInto(&sb)
+	if m.extras != nil {
+		m.extras.formatInto(&sb)
+	}
 
 	sb.WriteString(" }")
 
 	return sb.String()
 }
 
+type rxMessageJSON struct {
+	CorpID     string      `json:"corp_id"`
+	FromUserID string      `json:"from_user_id"`
+	SendTime   int64       `json:"send_time"`
+	MsgType    MessageType `json:"msg_type"`
+	MsgID      int64       `json:"msg_id,omitempty"`
+	AgentID    int64       `json:"agent_id,omitempty"`
+	Event      EventType   `json:"event,omitempty"`
+	EventKey   string      `json:"event_key,omitempty"`
+	ChangeType ChangeType  `json:"change_type,omitempty"`
+	Extras     messageKind `json:"extras,omitempty"`
+	RawXML     string      `json:"raw_xml,omitempty"`
+}
+
+// MarshalJSON 将消息序列化为 JSON
+//
+// send_time 为 Unix 时间戳（秒）；extras 为消息类型特有的字段，键名与 SDK 中的
+// 字段名一致；raw_xml 为解密后的原始 XML 消息体，SDK 尚未支持的消息类型可据此解析。
+func (m *RxMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.intoJSONObject())
+}
+
+func (m *RxMessage) intoJSONObject() *rxMessageJSON {
+	obj := rxMessageJSON{
+		CorpID:     m.CorpID,
+		FromUserID: m.FromUserID,
+		SendTime:   m.SendTime.Unix(),
+		MsgType:    m.MsgType,
+		MsgID:      m.MsgID,
+		AgentID:    m.AgentID,
+		Event:      m.Event,
+		EventKey:   m.EventKey,
+		ChangeType: m.ChangeType,
+		RawXML:     string(m.rawXML),
+	}
+	// unknown messages carry nothing but the raw XML
+	if _, ok := m.extras.(*rxUnknownMessage); !ok {
+		obj.Extras = m.extras
+	}
+	return &obj
+}
+
+// RawXML 返回解密后的原始 XML 消息体，可用于解析 SDK 尚未支持的字段
+//
+// 返回值不应被修改。非由回调解析而来的消息返回 nil。
+func (m *RxMessage) RawXML() []byte {
+	return m.rawXML
+}
+
 // Text 如果消息为文本类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Text() (TextMessageExtras, bool) {
 	y, ok := m.extras.(TextMessageExtras)
@@ -109,8 +171,12 @@ func (m *RxMessage) Video() (VideoMessageExtras, bool) {
 
 // Location 如果消息为位置类型，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) Location() (LocationMessageExtras, bool) {
-	y, ok := m.extras.(LocationMessageExtras)
-	return y, ok
+	// location_select 事件的方法集与位置消息相同，因此需判断具体类型
+	y, ok := m.extras.(*rxLocationMessageSpecifics)
+	if !ok {
+		return nil, false
+	}
+	return y, true
 }
 
 // Link 如果消息为链接类型，则拿出相应的消息参数，否则返回 nil, false
@@ -161,8 +227,151 @@ func (m *RxMessage) EventChangeExternalChat() (EventChangeExternalChat, bool) {
 	return y, ok
 }
 
+// EventChangeExternalTag 如果消息为企业客户标签变更事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeExternalTag() (EventChangeExternalTag, bool) {
+	y, ok := m.extras.(*rxEventChangeExternalTag)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
 // EventSysApprovalChange 如果消息为审批申请状态变化回调通知，则拿出相应的消息参数，否则返回 nil, false
 func (m *RxMessage) EventSysApprovalChange() (EventSysApprovalChange, bool) {
 	y, ok := m.extras.(EventSysApprovalChange)
 	return y, ok
 }
+
+// EventChangeContactCreateUser 如果消息为新增成员事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeContactCreateUser() (EventChangeContactCreateUser, bool) {
+	// 通讯录变更事件的参数接口互有包含关系（如 UpdateUser 包含 CreateUser 的全部方法），
+	// 因此这几个事件按具体类型判断
+	y, ok := m.extras.(*rxEventChangeContactCreateUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeContactUpdateUser 如果消息为更新成员事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeContactUpdateUser() (EventChangeContactUpdateUser, bool) {
+	y, ok := m.extras.(*rxEventChangeContactUpdateUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeContactDeleteUser 如果消息为删除成员事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeContactDeleteUser() (EventChangeContactDeleteUser, bool) {
+	y, ok := m.extras.(*rxEventChangeContactDeleteUser)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeContactCreateParty 如果消息为新增部门事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeContactCreateParty() (EventChangeContactCreateParty, bool) {
+	y, ok := m.extras.(*rxEventChangeContactCreateParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeContactUpdateParty 如果消息为更新部门事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeContactUpdateParty() (EventChangeContactUpdateParty, bool) {
+	y, ok := m.extras.(*rxEventChangeContactUpdateParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeContactDeleteParty 如果消息为删除部门事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeContactDeleteParty() (EventChangeContactDeleteParty, bool) {
+	y, ok := m.extras.(*rxEventChangeContactDeleteParty)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventChangeContactUpdateTag 如果消息为标签成员变更事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventChangeContactUpdateTag() (EventChangeContactUpdateTag, bool) {
+	y, ok := m.extras.(*rxEventChangeContactUpdateTag)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventMenu 如果消息为点击菜单拉取消息、点击菜单跳转链接或进入应用事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventMenu() (EventMenu, bool) {
+	y, ok := m.extras.(*rxEventMenu)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventLocation 如果消息为上报地理位置事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventLocation() (EventLocation, bool) {
+	y, ok := m.extras.(*rxEventLocation)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventScancode 如果消息为扫码推事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventScancode() (EventScancode, bool) {
+	y, ok := m.extras.(*rxEventScancode)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventPic 如果消息为弹出发图器事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventPic() (EventPic, bool) {
+	y, ok := m.extras.(*rxEventPic)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventLocationSelect 如果消息为弹出地理位置选择器事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventLocationSelect() (EventLocationSelect, bool) {
+	y, ok := m.extras.(*rxEventLocationSelect)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventBatchJobResult 如果消息为异步任务完成事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventBatchJobResult() (EventBatchJobResult, bool) {
+	y, ok := m.extras.(*rxEventBatchJobResult)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// EventTemplateCardEvent 如果消息为模板卡片事件，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) EventTemplateCardEvent() (EventTemplateCardEvent, bool) {
+	y, ok := m.extras.(*rxEventTemplateCardEvent)
+	if !ok {
+		return nil, false
+	}
+	return y, true
+}
+
+// Unknown 如果消息为本 SDK 尚不认识的类型，则拿出相应的消息参数，否则返回 nil, false
+func (m *RxMessage) Unknown() (UnknownMessageExtras, bool) {
+	y, ok := m.extras.(UnknownMessageExtras)
+	return y, ok
+}
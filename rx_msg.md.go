@@ -18,7 +18,7 @@ type rxMessageCommon struct {
 	AgentID int64 `xml:"AgentID"`
 	// Event 事件类型 MsgType为event存在
 	Event EventType `xml:"Event"`
-	// Event 事件类型 EventKey为event存在
+	// EventKey 事件KEY值 Event为click、view等存在
 	EventKey string `xml:"EventKey"`
 	// ChangeType 变更类型 Event为change_external_contact存在
 	ChangeType ChangeType `xml:"ChangeType"`
@@ -51,15 +51,54 @@ const MessageTypeEvent MessageType = "event"
 // EventType 事件类型
 type EventType string
 
+// EventTypeClick 点击菜单拉取消息的事件
 const EventTypeClick EventType = "click"
+
+// EventTypeView 点击菜单跳转链接的事件
 const EventTypeView EventType = "view"
 
+// EventTypeEnterAgent 进入应用事件
+const EventTypeEnterAgent EventType = "enter_agent"
+
+// EventTypeLocation 上报地理位置事件
+const EventTypeLocation EventType = "LOCATION"
+
+// EventTypeScancodePush 扫码推事件的事件
+const EventTypeScancodePush EventType = "scancode_push"
+
+// EventTypeScancodeWaitmsg 扫码推事件且弹出“消息接收中”提示框的事件
+const EventTypeScancodeWaitmsg EventType = "scancode_waitmsg"
+
+// EventTypePicSysphoto 弹出系统拍照发图的事件
+const EventTypePicSysphoto EventType = "pic_sysphoto"
+
+// EventTypePicPhotoOrAlbum 弹出拍照或者相册发图的事件
+const EventTypePicPhotoOrAlbum EventType = "pic_photo_or_album"
+
+// EventTypePicWeixin 弹出企业微信相册发图器的事件
+const EventTypePicWeixin EventType = "pic_weixin"
+
+// EventTypeLocationSelect 弹出地理位置选择器的事件
+const EventTypeLocationSelect EventType = "location_select"
+
+// EventTypeBatchJobResult 异步任务完成事件
+const EventTypeBatchJobResult EventType = "batch_job_result"
+
+// EventTypeTemplateCardEvent 模板卡片事件
+const EventTypeTemplateCardEvent EventType = "template_card_event"
+
+// EventTypeChangeContact 通讯录变更事件
+const EventTypeChangeContact EventType = "change_contact"
+
 // EventTypeChangeExternalContact 企业客户事件
 const EventTypeChangeExternalContact EventType = "change_external_contact"
 
 // EventTypeChangeExternalChat 客户群变更事件
 const EventTypeChangeExternalChat EventType = "change_external_chat"
 
+// EventTypeChangeExternalTag 企业客户标签变更事件
+const EventTypeChangeExternalTag EventType = "change_external_tag"
+
 // EventTypeSysApprovalChange 审批申请状态变化回调通知
 const EventTypeSysApprovalChange EventType = "sys_approval_change"
 
@@ -84,6 +123,90 @@ const ChangeTypeDelFollowUser ChangeType = "del_follow_user"
 // ChangeTypeTransferFail 客户接替失败事件
 const ChangeTypeTransferFail ChangeType = "transfer_fail"
 
+// ChangeTypeCreate 创建事件，如客户群创建、企业客户标签创建
+const ChangeTypeCreate ChangeType = "create"
+
+// ChangeTypeUpdate 变更事件，如客户群变更、企业客户标签变更
+const ChangeTypeUpdate ChangeType = "update"
+
+// ChangeTypeDelete 删除事件，如企业客户标签删除
+const ChangeTypeDelete ChangeType = "delete"
+
+// ChangeTypeDismiss 解散事件，如客户群解散
+const ChangeTypeDismiss ChangeType = "dismiss"
+
+// ChangeTypeShuffle 重排事件，如企业客户标签重排
+const ChangeTypeShuffle ChangeType = "shuffle"
+
+// ChangeTypeCreateUser 新增成员事件
+const ChangeTypeCreateUser ChangeType = "create_user"
+
+// ChangeTypeUpdateUser 更新成员事件
+const ChangeTypeUpdateUser ChangeType = "update_user"
+
+// ChangeTypeDeleteUser 删除成员事件
+const ChangeTypeDeleteUser ChangeType = "delete_user"
+
+// ChangeTypeCreateParty 新增部门事件
+const ChangeTypeCreateParty ChangeType = "create_party"
+
+// ChangeTypeUpdateParty 更新部门事件
+const ChangeTypeUpdateParty ChangeType = "update_party"
+
+// ChangeTypeDeleteParty 删除部门事件
+const ChangeTypeDeleteParty ChangeType = "delete_party"
+
+// ChangeTypeUpdateTag 标签成员变更事件
+const ChangeTypeUpdateTag ChangeType = "update_tag"
+
+// ExternalChatUpdateDetail 客户群变更详情
+type ExternalChatUpdateDetail string
+
+// ExternalChatUpdateDetailAddMember 成员入群
+const ExternalChatUpdateDetailAddMember ExternalChatUpdateDetail = "add_member"
+
+// ExternalChatUpdateDetailDelMember 成员退群
+const ExternalChatUpdateDetailDelMember ExternalChatUpdateDetail = "del_member"
+
+// ExternalChatUpdateDetailChangeOwner 群主变更
+const ExternalChatUpdateDetailChangeOwner ExternalChatUpdateDetail = "change_owner"
+
+// ExternalChatUpdateDetailChangeName 群名变更
+const ExternalChatUpdateDetailChangeName ExternalChatUpdateDetail = "change_name"
+
+// ExternalChatUpdateDetailChangeNotice 群公告变更
+const ExternalChatUpdateDetailChangeNotice ExternalChatUpdateDetail = "change_notice"
+
+// ExternalChatJoinScene 客户群成员的入群方式
+type ExternalChatJoinScene int
+
+// ExternalChatJoinSceneInvite 由成员邀请入群（直接邀请入群）
+const ExternalChatJoinSceneInvite ExternalChatJoinScene = 0
+
+// ExternalChatJoinSceneInviteLink 由成员邀请入群（通过邀请链接入群）
+const ExternalChatJoinSceneInviteLink ExternalChatJoinScene = 1
+
+// ExternalChatJoinSceneQRCode 通过扫描群二维码入群
+const ExternalChatJoinSceneQRCode ExternalChatJoinScene = 3
+
+// ExternalChatQuitScene 客户群成员的退群方式
+type ExternalChatQuitScene int
+
+// ExternalChatQuitSceneSelf 自己退群
+const ExternalChatQuitSceneSelf ExternalChatQuitScene = 0
+
+// ExternalChatQuitSceneRemoved 群主/群管理员移出
+const ExternalChatQuitSceneRemoved ExternalChatQuitScene = 1
+
+// ExternalTagType 企业客户标签变更事件中变更的对象
+type ExternalTagType string
+
+// ExternalTagTypeTag 标签
+const ExternalTagTypeTag ExternalTagType = "tag"
+
+// ExternalTagTypeTagGroup 标签组
+const ExternalTagTypeTagGroup ExternalTagType = "tag_group"
+
 // rxTextMessageSpecifics 接收的文本消息，特有字段
 type rxTextMessageSpecifics struct {
 	// Content 文本消息内容
@@ -206,10 +329,28 @@ type rxEventChangeExternalChat struct {
 	ToUserName string `xml:"ToUserName"`
 	// FromUserName 此事件该值固定为sys，表示该消息由系统生成
 	FromUserName string `xml:"FromUserName"`
-	// FailReason 接替失败的原因, customer_refused-客户拒绝， customer_limit_exceed-接替成员的客户数达到上限
-	FailReason string `xml:"FailReason"`
 	// ChatID 群ID
 	ChatID string `xml:"ChatId"`
+	// UpdateDetail 变更详情，ChangeType为update时存在
+	UpdateDetail ExternalChatUpdateDetail `xml:"UpdateDetail"`
+	// JoinScene 当是成员入群时有值，表示成员的入群方式
+	JoinScene ExternalChatJoinScene `xml:"JoinScene"`
+	// QuitScene 当是成员退群时有值，表示成员的退群方式
+	QuitScene ExternalChatQuitScene `xml:"QuitScene"`
+	// MemChangeCnt 当是成员入群或退群时有值，表示成员变更数量
+	MemChangeCnt int `xml:"MemChangeCnt"`
+	// MemChangeList 当是成员入群或退群时有值，变更的成员列表
+	MemChangeList []string `xml:"MemChangeList>Item"`
+}
+
+// rxEventChangeExternalTag 接收的事件消息，企业客户标签变更事件
+type rxEventChangeExternalTag struct {
+	// ID 标签或标签组的ID；ChangeType为shuffle时，为标签组ID（为空时表示对所有标签组重排）
+	ID string `xml:"Id"`
+	// TagType 创建、变更或删除的是标签还是标签组，ChangeType为shuffle时不存在
+	TagType ExternalTagType `xml:"TagType"`
+	// StrategyID 标签或标签组所属的规则组id，只有规则组标签才有此字段
+	StrategyID int64 `xml:"StrategyId"`
 }
 
 // rxEventSysApprovalChange 接收的事件消息，审批申请状态变化回调通知
@@ -217,3 +358,233 @@ type rxEventSysApprovalChange struct {
 	// ApprovalInfo 审批信息、
 	ApprovalInfo OAApprovalInfo `xml:"ApprovalInfo"`
 }
+
+// rxEventChangeContactCreateUser 接收的事件消息，新增成员事件
+type rxEventChangeContactCreateUser struct {
+	// UserID 成员UserID
+	UserID string `xml:"UserID"`
+	// Name 成员名称
+	Name string `xml:"Name"`
+	// Department 成员部门列表，以逗号分隔
+	Department string `xml:"Department"`
+	// MainDepartment 主部门
+	MainDepartment int64 `xml:"MainDepartment"`
+	// IsLeaderInDept 表示所在部门是否为部门负责人，以逗号分隔，0-否，1-是，顺序与Department字段的部门逐一对应
+	IsLeaderInDept string `xml:"IsLeaderInDept"`
+	// DirectLeader 直属上级UserID，以逗号分隔，最多5个
+	DirectLeader string `xml:"DirectLeader"`
+	// Position 职位信息
+	Position string `xml:"Position"`
+	// Mobile 手机号码
+	Mobile string `xml:"Mobile"`
+	// Gender 性别，1表示男性，2表示女性
+	Gender UserGender `xml:"Gender"`
+	// Email 邮箱
+	Email string `xml:"Email"`
+	// BizMail 企业邮箱
+	BizMail string `xml:"BizMail"`
+	// Status 激活状态：1=已激活 2=已禁用 4=未激活
+	Status UserStatus `xml:"Status"`
+	// Avatar 头像url
+	Avatar string `xml:"Avatar"`
+	// Alias 成员别名
+	Alias string `xml:"Alias"`
+	// Telephone 座机
+	Telephone string `xml:"Telephone"`
+	// Address 地址
+	Address string `xml:"Address"`
+	// ExtAttr 扩展属性
+	ExtAttr []ChangeContactExtAttr `xml:"ExtAttr>Item"`
+}
+
+// rxEventChangeContactUpdateUser 接收的事件消息，更新成员事件
+type rxEventChangeContactUpdateUser struct {
+	// UserID 成员UserID
+	UserID string `xml:"UserID"`
+	// NewUserID 新的UserID，变更时推送（userid由系统生成时可更改一次）
+	NewUserID string `xml:"NewUserID"`
+	// Name 成员名称
+	Name string `xml:"Name"`
+	// Department 成员部门列表，以逗号分隔
+	Department string `xml:"Department"`
+	// MainDepartment 主部门
+	MainDepartment int64 `xml:"MainDepartment"`
+	// IsLeaderInDept 表示所在部门是否为部门负责人，以逗号分隔，0-否，1-是，顺序与Department字段的部门逐一对应
+	IsLeaderInDept string `xml:"IsLeaderInDept"`
+	// DirectLeader 直属上级UserID，以逗号分隔，最多5个
+	DirectLeader string `xml:"DirectLeader"`
+	// Position 职位信息
+	Position string `xml:"Position"`
+	// Mobile 手机号码
+	Mobile string `xml:"Mobile"`
+	// Gender 性别，1表示男性，2表示女性
+	Gender UserGender `xml:"Gender"`
+	// Email 邮箱
+	Email string `xml:"Email"`
+	// BizMail 企业邮箱
+	BizMail string `xml:"BizMail"`
+	// Status 激活状态：1=已激活 2=已禁用 4=未激活
+	Status UserStatus `xml:"Status"`
+	// Avatar 头像url
+	Avatar string `xml:"Avatar"`
+	// Alias 成员别名
+	Alias string `xml:"Alias"`
+	// Telephone 座机
+	Telephone string `xml:"Telephone"`
+	// Address 地址
+	Address string `xml:"Address"`
+	// ExtAttr 扩展属性
+	ExtAttr []ChangeContactExtAttr `xml:"ExtAttr>Item"`
+}
+
+// rxEventChangeContactDeleteUser 接收的事件消息，删除成员事件
+type rxEventChangeContactDeleteUser struct {
+	// UserID 成员UserID
+	UserID string `xml:"UserID"`
+}
+
+// rxEventChangeContactCreateParty 接收的事件消息，新增部门事件
+type rxEventChangeContactCreateParty struct {
+	// ID 部门Id
+	ID int64 `xml:"Id"`
+	// Name 部门名称
+	Name string `xml:"Name"`
+	// ParentID 父部门id
+	ParentID int64 `xml:"ParentId"`
+	// Order 部门排序
+	Order uint32 `xml:"Order"`
+}
+
+// rxEventChangeContactUpdateParty 接收的事件消息，更新部门事件
+type rxEventChangeContactUpdateParty struct {
+	// ID 部门Id
+	ID int64 `xml:"Id"`
+	// Name 部门名称，仅当该字段发生变更时传递
+	Name string `xml:"Name"`
+	// ParentID 父部门id，仅当该字段发生变更时传递
+	ParentID int64 `xml:"ParentId"`
+}
+
+// rxEventChangeContactDeleteParty 接收的事件消息，删除部门事件
+type rxEventChangeContactDeleteParty struct {
+	// ID 部门Id
+	ID int64 `xml:"Id"`
+}
+
+// rxEventChangeContactUpdateTag 接收的事件消息，标签成员变更事件
+type rxEventChangeContactUpdateTag struct {
+	// TagID 标签Id
+	TagID int64 `xml:"TagId"`
+	// AddUserItems 标签中新增的成员userid列表，用逗号分隔
+	AddUserItems string `xml:"AddUserItems"`
+	// DelUserItems 标签中删除的成员userid列表，用逗号分隔
+	DelUserItems string `xml:"DelUserItems"`
+	// AddPartyItems 标签中新增的部门id列表，用逗号分隔
+	AddPartyItems string `xml:"AddPartyItems"`
+	// DelPartyItems 标签中删除的部门id列表，用逗号分隔
+	DelPartyItems string `xml:"DelPartyItems"`
+}
+
+// ChangeContactExtAttr 通讯录变更事件中成员的扩展属性
+type ChangeContactExtAttr struct {
+	// Name 扩展属性名称
+	Name string `xml:"Name"`
+	// Type 扩展属性类型: 0-文本 1-网页
+	Type int `xml:"Type"`
+	// TextValue 文本属性内容，Type为0时存在
+	TextValue string `xml:"Text>Value"`
+	// WebTitle 网页的展示标题，Type为1时存在
+	WebTitle string `xml:"Web>Title"`
+	// WebURL 网页的url，Type为1时存在
+	WebURL string `xml:"Web>Url"`
+}
+
+// rxEventMenu 接收的事件消息，点击菜单拉取消息、点击菜单跳转链接或进入应用的事件
+type rxEventMenu struct {
+	// EventKey 事件KEY值，与自定义菜单接口中KEY值对应；跳转链接事件为设置的跳转URL；进入应用事件为空
+	EventKey string `xml:"EventKey"`
+}
+
+// rxEventLocation 接收的事件消息，上报地理位置事件
+type rxEventLocation struct {
+	// Latitude 地理位置纬度
+	Latitude float64 `xml:"Latitude"`
+	// Longitude 地理位置经度
+	Longitude float64 `xml:"Longitude"`
+	// Precision 地理位置精度
+	Precision float64 `xml:"Precision"`
+	// AppType app类型，在企业微信固定返回wxwork，在微信不返回该字段
+	AppType string `xml:"AppType"`
+}
+
+// rxEventScancode 接收的事件消息，扫码推事件
+type rxEventScancode struct {
+	// EventKey 事件KEY值，由开发者在创建菜单时设定
+	EventKey string `xml:"EventKey"`
+	// ScanType 扫描类型，一般是qrcode
+	ScanType string `xml:"ScanCodeInfo>ScanType"`
+	// ScanResult 扫描结果，即二维码对应的字符串信息
+	ScanResult string `xml:"ScanCodeInfo>ScanResult"`
+}
+
+// rxEventPic 接收的事件消息，弹出发图器的事件
+type rxEventPic struct {
+	// EventKey 事件KEY值，由开发者在创建菜单时设定
+	EventKey string `xml:"EventKey"`
+	// Count 发送的图片数量
+	Count int `xml:"SendPicsInfo>Count"`
+	// PicMd5Sums 图片的MD5值，开发者若需要，可用于验证接收到图片
+	PicMd5Sums []string `xml:"SendPicsInfo>PicList>item>PicMd5Sum"`
+}
+
+// rxEventLocationSelect 接收的事件消息，弹出地理位置选择器的事件
+type rxEventLocationSelect struct {
+	// EventKey 事件KEY值，由开发者在创建菜单时设定
+	EventKey string `xml:"EventKey"`
+	// Lat 地理位置纬度
+	Lat float64 `xml:"SendLocationInfo>Location_X"`
+	// Lon 地理位置经度
+	Lon float64 `xml:"SendLocationInfo>Location_Y"`
+	// Scale 精度，可理解为精度或者比例尺、越精细的话 scale越高
+	Scale int `xml:"SendLocationInfo>Scale"`
+	// Label 地理位置的字符串信息
+	Label string `xml:"SendLocationInfo>Label"`
+	// PoiName POI的名字，可能为空
+	PoiName string `xml:"SendLocationInfo>Poiname"`
+	// AppType app类型，在企业微信固定返回wxwork，在微信不返回该字段
+	AppType string `xml:"AppType"`
+}
+
+// rxEventBatchJobResult 接收的事件消息，异步任务完成事件
+type rxEventBatchJobResult struct {
+	// JobID 异步任务id
+	JobID string `xml:"BatchJob>JobId"`
+	// JobType 操作类型，字符串，目前分别有：sync_user(增量更新成员)、 replace_user(全量覆盖成员）、invite_user(邀请成员关注）、replace_party(全量覆盖部门)
+	JobType string `xml:"BatchJob>JobType"`
+	// ErrCode 返回码
+	ErrCode int64 `xml:"BatchJob>ErrCode"`
+	// ErrMsg 对返回码的文本描述内容
+	ErrMsg string `xml:"BatchJob>ErrMsg"`
+}
+
+// rxEventTemplateCardEvent 接收的事件消息，模板卡片事件
+type rxEventTemplateCardEvent struct {
+	// EventKey 与发送模板卡片消息时指定的按钮btn:key值相同
+	EventKey string `xml:"EventKey"`
+	// TaskID 与发送模板卡片消息时指定的task_id相同
+	TaskID string `xml:"TaskId"`
+	// CardType 通用模板卡片的类型
+	CardType TemplateCardType `xml:"CardType"`
+	// ResponseCode 用于调用更新卡片接口的ResponseCode，24小时内有效，且只能使用一次
+	ResponseCode string `xml:"ResponseCode"`
+	// SelectedItems 用户点击提交的选择类数据
+	SelectedItems []TemplateCardEventSelectedItem `xml:"SelectedItems>SelectedItem"`
+}
+
+// TemplateCardEventSelectedItem 模板卡片事件中用户提交的选择类数据
+type TemplateCardEventSelectedItem struct {
+	// QuestionKey 问题的key值
+	QuestionKey string `xml:"QuestionKey"`
+	// OptionIDs 选择的选项id列表
+	OptionIDs []string `xml:"OptionIds>OptionId"`
+}
@@ -0,0 +1,303 @@
+package workwx
+
+import (
+	"fmt"
+	"io"
+)
+
+// EventMenu 点击菜单拉取消息、点击菜单跳转链接与进入应用事件的参数。
+type EventMenu interface {
+	messageKind
+
+	// GetEventKey 事件KEY值
+	//
+	// 点击菜单拉取消息事件为自定义菜单接口中的 KEY 值，点击菜单跳转链接事件为
+	// 设置的跳转 URL，进入应用事件为空。
+	GetEventKey() string
+}
+
+var _ EventMenu = (*rxEventMenu)(nil)
+
+func (r *rxEventMenu) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "EventKey: %#v", r.EventKey)
+}
+
+func (r *rxEventMenu) GetEventKey() string {
+	return r.EventKey
+}
+
+// EventLocation 上报地理位置事件的参数。
+type EventLocation interface {
+	messageKind
+
+	// GetLatitude 地理位置纬度
+	GetLatitude() float64
+
+	// GetLongitude 地理位置经度
+	GetLongitude() float64
+
+	// GetPrecision 地理位置精度
+	GetPrecision() float64
+}
+
+var _ EventLocation = (*rxEventLocation)(nil)
+
+func (r *rxEventLocation) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"Latitude: %#v, Longitude: %#v, Precision: %#v",
+		r.Latitude,
+		r.Longitude,
+		r.Precision,
+	)
+}
+
+func (r *rxEventLocation) GetLatitude() float64 {
+	return r.Latitude
+}
+
+func (r *rxEventLocation) GetLongitude() float64 {
+	return r.Longitude
+}
+
+func (r *rxEventLocation) GetPrecision() float64 {
+	return r.Precision
+}
+
+// EventScancode 扫码推事件（scancode_push、scancode_waitmsg）的参数。
+type EventScancode interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，由开发者在创建菜单时设定
+	GetEventKey() string
+
+	// GetScanType 扫描类型，一般是qrcode
+	GetScanType() string
+
+	// GetScanResult 扫描结果，即二维码对应的字符串信息
+	GetScanResult() string
+}
+
+var _ EventScancode = (*rxEventScancode)(nil)
+
+func (r *rxEventScancode) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, ScanType: %#v, ScanResult: %#v",
+		r.EventKey,
+		r.ScanType,
+		r.ScanResult,
+	)
+}
+
+func (r *rxEventScancode) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventScancode) GetScanType() string {
+	return r.ScanType
+}
+
+func (r *rxEventScancode) GetScanResult() string {
+	return r.ScanResult
+}
+
+// EventPic 弹出发图器事件（pic_sysphoto、pic_photo_or_album、pic_weixin）的参数。
+type EventPic interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，由开发者在创建菜单时设定
+	GetEventKey() string
+
+	// GetCount 发送的图片数量
+	GetCount() int
+
+	// GetPicMd5Sums 各图片的MD5值，可用于验证接收到的图片
+	GetPicMd5Sums() []string
+}
+
+var _ EventPic = (*rxEventPic)(nil)
+
+func (r *rxEventPic) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, Count: %d, PicMd5Sums: %#v",
+		r.EventKey,
+		r.Count,
+		r.PicMd5Sums,
+	)
+}
+
+func (r *rxEventPic) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventPic) GetCount() int {
+	return r.Count
+}
+
+func (r *rxEventPic) GetPicMd5Sums() []string {
+	return r.PicMd5Sums
+}
+
+// EventLocationSelect 弹出地理位置选择器事件的参数。
+type EventLocationSelect interface {
+	messageKind
+
+	// GetEventKey 事件KEY值，由开发者在创建菜单时设定
+	GetEventKey() string
+
+	// GetLatitude 地理位置纬度
+	GetLatitude() float64
+
+	// GetLongitude 地理位置经度
+	GetLongitude() float64
+
+	// GetScale 精度，可理解为精度或者比例尺，越精细的话 scale 越高
+	GetScale() int
+
+	// GetLabel 地理位置的字符串信息
+	GetLabel() string
+
+	// GetPoiName POI的名字，可能为空
+	GetPoiName() string
+}
+
+var _ EventLocationSelect = (*rxEventLocationSelect)(nil)
+
+func (r *rxEventLocationSelect) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, Latitude: %#v, Longitude: %#v, Scale: %d, Label: %#v, PoiName: %#v",
+		r.EventKey,
+		r.Lat,
+		r.Lon,
+		r.Scale,
+		r.Label,
+		r.PoiName,
+	)
+}
+
+func (r *rxEventLocationSelect) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventLocationSelect) GetLatitude() float64 {
+	return r.Lat
+}
+
+func (r *rxEventLocationSelect) GetLongitude() float64 {
+	return r.Lon
+}
+
+func (r *rxEventLocationSelect) GetScale() int {
+	return r.Scale
+}
+
+func (r *rxEventLocationSelect) GetLabel() string {
+	return r.Label
+}
+
+func (r *rxEventLocationSelect) GetPoiName() string {
+	return r.PoiName
+}
+
+// EventBatchJobResult 异步任务完成事件的参数。
+type EventBatchJobResult interface {
+	messageKind
+
+	// GetJobID 异步任务id
+	GetJobID() string
+
+	// GetJobType 操作类型，如 sync_user、replace_user、invite_user、replace_party
+	GetJobType() string
+
+	// GetErrCode 返回码
+	GetErrCode() int64
+
+	// GetErrMsg 对返回码的文本描述内容
+	GetErrMsg() string
+}
+
+var _ EventBatchJobResult = (*rxEventBatchJobResult)(nil)
+
+func (r *rxEventBatchJobResult) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"JobID: %#v, JobType: %#v, ErrCode: %d, ErrMsg: %#v",
+		r.JobID,
+		r.JobType,
+		r.ErrCode,
+		r.ErrMsg,
+	)
+}
+
+func (r *rxEventBatchJobResult) GetJobID() string {
+	return r.JobID
+}
+
+func (r *rxEventBatchJobResult) GetJobType() string {
+	return r.JobType
+}
+
+func (r *rxEventBatchJobResult) GetErrCode() int64 {
+	return r.ErrCode
+}
+
+func (r *rxEventBatchJobResult) GetErrMsg() string {
+	return r.ErrMsg
+}
+
+// EventTemplateCardEvent 模板卡片事件的参数。
+type EventTemplateCardEvent interface {
+	messageKind
+
+	// GetEventKey 与发送模板卡片消息时指定的按钮 key 值相同
+	GetEventKey() string
+
+	// GetTaskID 与发送模板卡片消息时指定的 task_id 相同
+	GetTaskID() string
+
+	// GetCardType 模板卡片的类型
+	GetCardType() TemplateCardType
+
+	// GetResponseCode 用于调用更新卡片接口的 ResponseCode，24小时内有效，且只能使用一次
+	GetResponseCode() string
+
+	// GetSelectedItems 用户点击提交的选择类数据
+	GetSelectedItems() []TemplateCardEventSelectedItem
+}
+
+var _ EventTemplateCardEvent = (*rxEventTemplateCardEvent)(nil)
+
+func (r *rxEventTemplateCardEvent) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"EventKey: %#v, TaskID: %#v, CardType: %#v, ResponseCode: %#v, SelectedItems: %#v",
+		r.EventKey,
+		r.TaskID,
+		r.CardType,
+		r.ResponseCode,
+		r.SelectedItems,
+	)
+}
+
+func (r *rxEventTemplateCardEvent) GetEventKey() string {
+	return r.EventKey
+}
+
+func (r *rxEventTemplateCardEvent) GetTaskID() string {
+	return r.TaskID
+}
+
+func (r *rxEventTemplateCardEvent) GetCardType() TemplateCardType {
+	return r.CardType
+}
+
+func (r *rxEventTemplateCardEvent) GetResponseCode() string {
+	return r.ResponseCode
+}
+
+func (r *rxEventTemplateCardEvent) GetSelectedItems() []TemplateCardEventSelectedItem {
+	return r.SelectedItems
+}
@@ -0,0 +1,543 @@
+package workwx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func extractChangeContactExtras(common rxMessageCommon, body []byte) (messageKind, error) {
+	var x messageKind
+	switch common.ChangeType {
+	case ChangeTypeCreateUser:
+		x = &rxEventChangeContactCreateUser{}
+	case ChangeTypeUpdateUser:
+		x = &rxEventChangeContactUpdateUser{}
+	case ChangeTypeDeleteUser:
+		x = &rxEventChangeContactDeleteUser{}
+	case ChangeTypeCreateParty:
+		x = &rxEventChangeContactCreateParty{}
+	case ChangeTypeUpdateParty:
+		x = &rxEventChangeContactUpdateParty{}
+	case ChangeTypeDeleteParty:
+		x = &rxEventChangeContactDeleteParty{}
+	case ChangeTypeUpdateTag:
+		x = &rxEventChangeContactUpdateTag{}
+	default:
+		return newRxUnknownMessage(body), nil
+	}
+
+	err := xml.Unmarshal(body, x)
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// splitRxStringList 拆分以逗号分隔的列表，空串返回 nil
+func splitRxStringList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func splitRxInt64List(s string) []int64 {
+	parts := splitRxStringList(s)
+	if parts == nil {
+		return nil
+	}
+
+	result := make([]int64, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+		if err != nil {
+			continue
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+func splitRxBoolList(s string) []bool {
+	parts := splitRxStringList(s)
+	if parts == nil {
+		return nil
+	}
+
+	result := make([]bool, len(parts))
+	for i, p := range parts {
+		result[i] = strings.TrimSpace(p) == "1"
+	}
+	return result
+}
+
+// EventChangeContactCreateUser 新增成员事件
+type EventChangeContactCreateUser interface {
+	messageKind
+
+	// GetUserID 成员UserID
+	GetUserID() string
+
+	// GetName 成员名称
+	GetName() string
+
+	// GetDepartments 成员部门列表
+	GetDepartments() []int64
+
+	// GetMainDepartment 主部门
+	GetMainDepartment() int64
+
+	// GetIsLeaderInDept 所在部门是否为部门负责人，顺序与 GetDepartments 逐一对应
+	GetIsLeaderInDept() []bool
+
+	// GetDirectLeaders 直属上级UserID列表
+	GetDirectLeaders() []string
+
+	// GetPosition 职位信息
+	GetPosition() string
+
+	// GetMobile 手机号码
+	GetMobile() string
+
+	// GetGender 性别
+	GetGender() UserGender
+
+	// GetEmail 邮箱
+	GetEmail() string
+
+	// GetBizMail 企业邮箱
+	GetBizMail() string
+
+	// GetStatus 激活状态
+	GetStatus() UserStatus
+
+	// GetAvatar 头像url
+	GetAvatar() string
+
+	// GetAlias 成员别名
+	GetAlias() string
+
+	// GetTelephone 座机
+	GetTelephone() string
+
+	// GetAddress 地址
+	GetAddress() string
+
+	// GetExtAttrs 扩展属性
+	GetExtAttrs() []ChangeContactExtAttr
+}
+
+var _ EventChangeContactCreateUser = (*rxEventChangeContactCreateUser)(nil)
+
+func (r *rxEventChangeContactCreateUser) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"UserID: %#v, Name: %#v, Department: %#v, MainDepartment: %d, IsLeaderInDept: %#v, Position: %#v, Mobile: %#v, Email: %#v",
+		r.UserID,
+		r.Name,
+		r.Department,
+		r.MainDepartment,
+		r.IsLeaderInDept,
+		r.Position,
+		r.Mobile,
+		r.Email,
+	)
+}
+
+func (r *rxEventChangeContactCreateUser) GetUserID() string {
+	return r.UserID
+}
+
+func (r *rxEventChangeContactCreateUser) GetName() string {
+	return r.Name
+}
+
+func (r *rxEventChangeContactCreateUser) GetDepartments() []int64 {
+	return splitRxInt64List(r.Department)
+}
+
+func (r *rxEventChangeContactCreateUser) GetMainDepartment() int64 {
+	return r.MainDepartment
+}
+
+func (r *rxEventChangeContactCreateUser) GetIsLeaderInDept() []bool {
+	return splitRxBoolList(r.IsLeaderInDept)
+}
+
+func (r *rxEventChangeContactCreateUser) GetDirectLeaders() []string {
+	return splitRxStringList(r.DirectLeader)
+}
+
+func (r *rxEventChangeContactCreateUser) GetPosition() string {
+	return r.Position
+}
+
+func (r *rxEventChangeContactCreateUser) GetMobile() string {
+	return r.Mobile
+}
+
+func (r *rxEventChangeContactCreateUser) GetGender() UserGender {
+	return r.Gender
+}
+
+func (r *rxEventChangeContactCreateUser) GetEmail() string {
+	return r.Email
+}
+
+func (r *rxEventChangeContactCreateUser) GetBizMail() string {
+	return r.BizMail
+}
+
+func (r *rxEventChangeContactCreateUser) GetStatus() UserStatus {
+	return r.Status
+}
+
+func (r *rxEventChangeContactCreateUser) GetAvatar() string {
+	return r.Avatar
+}
+
+func (r *rxEventChangeContactCreateUser) GetAlias() string {
+	return r.Alias
+}
+
+func (r *rxEventChangeContactCreateUser) GetTelephone() string {
+	return r.Telephone
+}
+
+func (r *rxEventChangeContactCreateUser) GetAddress() string {
+	return r.Address
+}
+
+func (r *rxEventChangeContactCreateUser) GetExtAttrs() []ChangeContactExtAttr {
+	return r.ExtAttr
+}
+
+// EventChangeContactUpdateUser 更新成员事件
+//
+// 除 UserID 外，只有发生变更的字段才会推送，未推送的字段取零值。
+type EventChangeContactUpdateUser interface {
+	messageKind
+
+	// GetUserID 成员UserID
+	GetUserID() string
+
+	// GetNewUserID 新的UserID，仅当UserID发生变更时推送
+	GetNewUserID() string
+
+	// GetName 成员名称
+	GetName() string
+
+	// GetDepartments 成员部门列表
+	GetDepartments() []int64
+
+	// GetMainDepartment 主部门
+	GetMainDepartment() int64
+
+	// GetIsLeaderInDept 所在部门是否为部门负责人，顺序与 GetDepartments 逐一对应
+	GetIsLeaderInDept() []bool
+
+	// GetDirectLeaders 直属上级UserID列表
+	GetDirectLeaders() []string
+
+	// GetPosition 职位信息
+	GetPosition() string
+
+	// GetMobile 手机号码
+	GetMobile() string
+
+	// GetGender 性别
+	GetGender() UserGender
+
+	// GetEmail 邮箱
+	GetEmail() string
+
+	// GetBizMail 企业邮箱
+	GetBizMail() string
+
+	// GetStatus 激活状态
+	GetStatus() UserStatus
+
+	// GetAvatar 头像url
+	GetAvatar() string
+
+	// GetAlias 成员别名
+	GetAlias() string
+
+	// GetTelephone 座机
+	GetTelephone() string
+
+	// GetAddress 地址
+	GetAddress() string
+
+	// GetExtAttrs 扩展属性
+	GetExtAttrs() []ChangeContactExtAttr
+}
+
+var _ EventChangeContactUpdateUser = (*rxEventChangeContactUpdateUser)(nil)
+
+func (r *rxEventChangeContactUpdateUser) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"UserID: %#v, NewUserID: %#v, Name: %#v, Department: %#v, MainDepartment: %d, IsLeaderInDept: %#v, Position: %#v, Mobile: %#v, Email: %#v",
+		r.UserID,
+		r.NewUserID,
+		r.Name,
+		r.Department,
+		r.MainDepartment,
+		r.IsLeaderInDept,
+		r.Position,
+		r.Mobile,
+		r.Email,
+	)
+}
+
+func (r *rxEventChangeContactUpdateUser) GetUserID() string {
+	return r.UserID
+}
+
+func (r *rxEventChangeContactUpdateUser) GetNewUserID() string {
+	return r.NewUserID
+}
+
+func (r *rxEventChangeContactUpdateUser) GetName() string {
+	return r.Name
+}
+
+func (r *rxEventChangeContactUpdateUser) GetDepartments() []int64 {
+	return splitRxInt64List(r.Department)
+}
+
+func (r *rxEventChangeContactUpdateUser) GetMainDepartment() int64 {
+	return r.MainDepartment
+}
+
+func (r *rxEventChangeContactUpdateUser) GetIsLeaderInDept() []bool {
+	return splitRxBoolList(r.IsLeaderInDept)
+}
+
+func (r *rxEventChangeContactUpdateUser) GetDirectLeaders() []string {
+	return splitRxStringList(r.DirectLeader)
+}
+
+func (r *rxEventChangeContactUpdateUser) GetPosition() string {
+	return r.Position
+}
+
+func (r *rxEventChangeContactUpdateUser) GetMobile() string {
+	return r.Mobile
+}
+
+func (r *rxEventChangeContactUpdateUser) GetGender() UserGender {
+	return r.Gender
+}
+
+func (r *rxEventChangeContactUpdateUser) GetEmail() string {
+	return r.Email
+}
+
+func (r *rxEventChangeContactUpdateUser) GetBizMail() string {
+	return r.BizMail
+}
+
+func (r *rxEventChangeContactUpdateUser) GetStatus() UserStatus {
+	return r.Status
+}
+
+func (r *rxEventChangeContactUpdateUser) GetAvatar() string {
+	return r.Avatar
+}
+
+func (r *rxEventChangeContactUpdateUser) GetAlias() string {
+	return r.Alias
+}
+
+func (r *rxEventChangeContactUpdateUser) GetTelephone() string {
+	return r.Telephone
+}
+
+func (r *rxEventChangeContactUpdateUser) GetAddress() string {
+	return r.Address
+}
+
+func (r *rxEventChangeContactUpdateUser) GetExtAttrs() []ChangeContactExtAttr {
+	return r.ExtAttr
+}
+
+// EventChangeContactDeleteUser 删除成员事件
+type EventChangeContactDeleteUser interface {
+	messageKind
+
+	// GetUserID 成员UserID
+	GetUserID() string
+}
+
+var _ EventChangeContactDeleteUser = (*rxEventChangeContactDeleteUser)(nil)
+
+func (r *rxEventChangeContactDeleteUser) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "UserID: %#v", r.UserID)
+}
+
+func (r *rxEventChangeContactDeleteUser) GetUserID() string {
+	return r.UserID
+}
+
+// EventChangeContactCreateParty 新增部门事件
+type EventChangeContactCreateParty interface {
+	messageKind
+
+	// GetID 部门Id
+	GetID() int64
+
+	// GetName 部门名称
+	GetName() string
+
+	// GetParentID 父部门id
+	GetParentID() int64
+
+	// GetOrder 部门排序
+	GetOrder() uint32
+}
+
+var _ EventChangeContactCreateParty = (*rxEventChangeContactCreateParty)(nil)
+
+func (r *rxEventChangeContactCreateParty) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"ID: %d, Name: %#v, ParentID: %d, Order: %d",
+		r.ID,
+		r.Name,
+		r.ParentID,
+		r.Order,
+	)
+}
+
+func (r *rxEventChangeContactCreateParty) GetID() int64 {
+	return r.ID
+}
+
+func (r *rxEventChangeContactCreateParty) GetName() string {
+	return r.Name
+}
+
+func (r *rxEventChangeContactCreateParty) GetParentID() int64 {
+	return r.ParentID
+}
+
+func (r *rxEventChangeContactCreateParty) GetOrder() uint32 {
+	return r.Order
+}
+
+// EventChangeContactUpdateParty 更新部门事件
+//
+// Name 与 ParentID 仅当发生变更时推送，未推送时取零值。
+type EventChangeContactUpdateParty interface {
+	messageKind
+
+	// GetID 部门Id
+	GetID() int64
+
+	// GetName 部门名称
+	GetName() string
+
+	// GetParentID 父部门id
+	GetParentID() int64
+}
+
+var _ EventChangeContactUpdateParty = (*rxEventChangeContactUpdateParty)(nil)
+
+func (r *rxEventChangeContactUpdateParty) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"ID: %d, Name: %#v, ParentID: %d",
+		r.ID,
+		r.Name,
+		r.ParentID,
+	)
+}
+
+func (r *rxEventChangeContactUpdateParty) GetID() int64 {
+	return r.ID
+}
+
+func (r *rxEventChangeContactUpdateParty) GetName() string {
+	return r.Name
+}
+
+func (r *rxEventChangeContactUpdateParty) GetParentID() int64 {
+	return r.ParentID
+}
+
+// EventChangeContactDeleteParty 删除部门事件
+type EventChangeContactDeleteParty interface {
+	messageKind
+
+	// GetID 部门Id
+	GetID() int64
+}
+
+var _ EventChangeContactDeleteParty = (*rxEventChangeContactDeleteParty)(nil)
+
+func (r *rxEventChangeContactDeleteParty) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "ID: %d", r.ID)
+}
+
+func (r *rxEventChangeContactDeleteParty) GetID() int64 {
+	return r.ID
+}
+
+// EventChangeContactUpdateTag 标签成员变更事件
+type EventChangeContactUpdateTag interface {
+	messageKind
+
+	// GetTagID 标签Id
+	GetTagID() int64
+
+	// GetAddUserItems 标签中新增的成员userid列表
+	GetAddUserItems() []string
+
+	// GetDelUserItems 标签中删除的成员userid列表
+	GetDelUserItems() []string
+
+	// GetAddPartyItems 标签中新增的部门id列表
+	GetAddPartyItems() []int64
+
+	// GetDelPartyItems 标签中删除的部门id列表
+	GetDelPartyItems() []int64
+}
+
+var _ EventChangeContactUpdateTag = (*rxEventChangeContactUpdateTag)(nil)
+
+func (r *rxEventChangeContactUpdateTag) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"TagID: %d, AddUserItems: %#v, DelUserItems: %#v, AddPartyItems: %#v, DelPartyItems: %#v",
+		r.TagID,
+		r.AddUserItems,
+		r.DelUserItems,
+		r.AddPartyItems,
+		r.DelPartyItems,
+	)
+}
+
+func (r *rxEventChangeContactUpdateTag) GetTagID() int64 {
+	return r.TagID
+}
+
+func (r *rxEventChangeContactUpdateTag) GetAddUserItems() []string {
+	return splitRxStringList(r.AddUserItems)
+}
+
+func (r *rxEventChangeContactUpdateTag) GetDelUserItems() []string {
+	return splitRxStringList(r.DelUserItems)
+}
+
+func (r *rxEventChangeContactUpdateTag) GetAddPartyItems() []int64 {
+	return splitRxInt64List(r.AddPartyItems)
+}
+
+func (r *rxEventChangeContactUpdateTag) GetDelPartyItems() []int64 {
+	return splitRxInt64List(r.DelPartyItems)
+}
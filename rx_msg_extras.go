@@ -63,10 +63,62 @@ func extractMessageExtras(common rxMessageCommon, body []byte) (messageKind, err
 
 	case MessageTypeEvent:
 		switch common.Event {
-		case EventTypeClick:
-			return nil, nil
-		case EventTypeView:
-			return nil, nil
+		case EventTypeClick, EventTypeView, EventTypeEnterAgent:
+			var x rxEventMenu
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
+		case EventTypeLocation:
+			var x rxEventLocation
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
+		case EventTypeScancodePush, EventTypeScancodeWaitmsg:
+			var x rxEventScancode
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
+		case EventTypePicSysphoto, EventTypePicPhotoOrAlbum, EventTypePicWeixin:
+			var x rxEventPic
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
+		case EventTypeLocationSelect:
+			var x rxEventLocationSelect
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
+		case EventTypeBatchJobResult:
+			var x rxEventBatchJobResult
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
+		case EventTypeTemplateCardEvent:
+			var x rxEventTemplateCardEvent
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
 		case EventTypeSysApprovalChange:
 			var x rxEventSysApprovalChange
 			err := xml.Unmarshal(body, &x)
@@ -125,8 +177,12 @@ func extractMessageExtras(common rxMessageCommon, body []byte) (messageKind, err
 				}
 				return &x, nil
 			default:
-				return nil, fmt.Errorf("unknown change type '%s'", common.ChangeType)
+				return newRxUnknownMessage(body), nil
 			}
+
+		case EventTypeChangeContact:
+			return extractChangeContactExtras(common, body)
+
 		case EventTypeChangeExternalChat:
 			var x rxEventChangeExternalChat
 			err := xml.Unmarshal(body, &x)
@@ -135,12 +191,20 @@ func extractMessageExtras(common rxMessageCommon, body []byte) (messageKind, err
 			}
 			return &x, nil
 
+		case EventTypeChangeExternalTag:
+			var x rxEventChangeExternalTag
+			err := xml.Unmarshal(body, &x)
+			if err != nil {
+				return nil, err
+			}
+			return &x, nil
+
 		default:
-			return nil, fmt.Errorf("unknown event '%s'", common.Event)
+			return newRxUnknownMessage(body), nil
 		}
 
 	default:
-		return nil, fmt.Errorf("unknown message type '%s'", common.MsgType)
+		return newRxUnknownMessage(body), nil
 	}
 }
 
@@ -566,6 +630,9 @@ func (r *rxEventTransferFail) GetFailReason() string {
 }
 
 // EventChangeExternalChat 客户群变更事件
+//
+// 创建（ChangeTypeCreate）、变更（ChangeTypeUpdate）与解散（ChangeTypeDismiss）
+// 客户群时推送，可通过 RxMessage.ChangeType 区分。
 type EventChangeExternalChat interface {
 	messageKind
 
@@ -578,8 +645,20 @@ type EventChangeExternalChat interface {
 	// GetFromUserName 此事件该值固定为sys，表示该消息由系统生成
 	GetFromUserName() string
 
-	// GetFailReason 接替失败的原因, customer_refused-客户拒绝， customer_limit_exceed-接替成员的客户数达到上限
-	GetFailReason() string
+	// GetUpdateDetail 变更详情，ChangeType为update时存在
+	GetUpdateDetail() ExternalChatUpdateDetail
+
+	// GetJoinScene 成员的入群方式，UpdateDetail为add_member时有意义
+	GetJoinScene() ExternalChatJoinScene
+
+	// GetQuitScene 成员的退群方式，UpdateDetail为del_member时有意义
+	GetQuitScene() ExternalChatQuitScene
+
+	// GetMemChangeCnt 成员变更数量，成员入群或退群时有值
+	GetMemChangeCnt() int
+
+	// GetMemChangeList 变更的成员列表，成员入群或退群时有值
+	GetMemChangeList() []string
 }
 
 var _ EventChangeExternalChat = (*rxEventChangeExternalChat)(nil)
@@ -587,11 +666,14 @@ var _ EventChangeExternalChat = (*rxEventChangeExternalChat)(nil)
 func (r *rxEventChangeExternalChat) formatInto(w io.Writer) {
 	_, _ = fmt.Fprintf(
 		w,
-		"ChatID: %#v, ToUserName: %#v, FromUserName: %#v, FailReason: %#v",
+		"ChatID: %#v, ToUserName: %#v, FromUserName: %#v, UpdateDetail: %#v, JoinScene: %d, QuitScene: %d, MemChangeCnt: %d",
 		r.ChatID,
 		r.ToUserName,
 		r.FromUserName,
-		r.FailReason,
+		r.UpdateDetail,
+		r.JoinScene,
+		r.QuitScene,
+		r.MemChangeCnt,
 	)
 }
 
@@ -607,8 +689,66 @@ func (r *rxEventChangeExternalChat) GetFromUserName() string {
 	return r.FromUserName
 }
 
-func (r *rxEventChangeExternalChat) GetFailReason() string {
-	return r.FailReason
+func (r *rxEventChangeExternalChat) GetUpdateDetail() ExternalChatUpdateDetail {
+	return r.UpdateDetail
+}
+
+func (r *rxEventChangeExternalChat) GetJoinScene() ExternalChatJoinScene {
+	return r.JoinScene
+}
+
+func (r *rxEventChangeExternalChat) GetQuitScene() ExternalChatQuitScene {
+	return r.QuitScene
+}
+
+func (r *rxEventChangeExternalChat) GetMemChangeCnt() int {
+	return r.MemChangeCnt
+}
+
+func (r *rxEventChangeExternalChat) GetMemChangeList() []string {
+	return r.MemChangeList
+}
+
+// EventChangeExternalTag 企业客户标签变更事件
+//
+// 创建（ChangeTypeCreate）、变更（ChangeTypeUpdate）、删除（ChangeTypeDelete）
+// 与重排（ChangeTypeShuffle）企业客户标签或标签组时推送，可通过
+// RxMessage.ChangeType 区分。
+type EventChangeExternalTag interface {
+	messageKind
+
+	// GetID 标签或标签组的ID；重排事件中为标签组ID，为空时表示对所有标签组重排
+	GetID() string
+
+	// GetTagType 变更的是标签还是标签组，重排事件中不存在
+	GetTagType() ExternalTagType
+
+	// GetStrategyID 标签或标签组所属的规则组id，只有规则组标签才有此字段
+	GetStrategyID() int64
+}
+
+var _ EventChangeExternalTag = (*rxEventChangeExternalTag)(nil)
+
+func (r *rxEventChangeExternalTag) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(
+		w,
+		"ID: %#v, TagType: %#v, StrategyID: %d",
+		r.ID,
+		r.TagType,
+		r.StrategyID,
+	)
+}
+
+func (r *rxEventChangeExternalTag) GetID() string {
+	return r.ID
+}
+
+func (r *rxEventChangeExternalTag) GetTagType() ExternalTagType {
+	return r.TagType
+}
+
+func (r *rxEventChangeExternalTag) GetStrategyID() int64 {
+	return r.StrategyID
 }
 
 // EventSysApprovalChange 审批申请状态变化回调通知
@@ -630,3 +770,34 @@ func (r rxEventSysApprovalChange) formatInto(w io.Writer) {
 func (r rxEventSysApprovalChange) GetApprovalInfo() OAApprovalInfo {
 	return r.ApprovalInfo
 }
+
+// UnknownMessageExtras 本 SDK 尚不认识的消息或事件类型的参数。
+//
+// 企业微信新增的消息、事件类型会以此形式交给处理器，而不是被拒绝；可通过
+// RxMessage 的 MsgType、Event、ChangeType 等字段判断类型，再自行解析原始 XML。
+type UnknownMessageExtras interface {
+	messageKind
+
+	// GetRawXML 返回解密后的原始 XML 消息体。
+	GetRawXML() []byte
+}
+
+type rxUnknownMessage struct {
+	raw []byte
+}
+
+var _ UnknownMessageExtras = (*rxUnknownMessage)(nil)
+
+func newRxUnknownMessage(body []byte) *rxUnknownMessage {
+	raw := make([]byte, len(body))
+	copy(raw, body)
+	return &rxUnknownMessage{raw: raw}
+}
+
+func (r *rxUnknownMessage) formatInto(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "RawXML: %#v", string(r.raw))
+}
+
+func (r *rxUnknownMessage) GetRawXML() []byte {
+	return r.raw
+}
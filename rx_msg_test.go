@@ -92,3 +92,260 @@ func TestRxMessageEventEditExternalContact(t *testing.T) {
 		})
 	})
 }
+
+func TestRxMessageUnknown(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		c.Convey("未知的事件类型", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[brand_new_event]]></Event><Foo><![CDATA[bar]]></Foo></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg, c.ShouldNotBeNil)
+			c.So(msg.MsgType, c.ShouldEqual, MessageTypeEvent)
+			c.So(msg.Event, c.ShouldEqual, EventType("brand_new_event"))
+			c.So(msg.RawXML(), c.ShouldResemble, body)
+			c.So(msg.String(), c.ShouldContainSubstring, "<Foo><![CDATA[bar]]></Foo>")
+
+			e, ok := msg.Unknown()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetRawXML(), c.ShouldResemble, body)
+		})
+
+		c.Convey("未知的消息类型与变更类型", func() {
+			for _, body := range []string{
+				"<xml><MsgType><![CDATA[brand_new_type]]></MsgType></xml>",
+				"<xml><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_contact]]></Event><ChangeType><![CDATA[brand_new_change]]></ChangeType></xml>",
+			} {
+				msg, err := fromEnvelope([]byte(body))
+				c.So(err, c.ShouldBeNil)
+				_, ok := msg.Unknown()
+				c.So(ok, c.ShouldBeTrue)
+			}
+		})
+
+		c.Convey("已知类型不应被视为未知", func() {
+			msg, err := fromEnvelope([]byte("<xml><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x]]></Content></xml>"))
+			c.So(err, c.ShouldBeNil)
+			_, ok := msg.Unknown()
+			c.So(ok, c.ShouldBeFalse)
+			c.So(msg.RawXML(), c.ShouldNotBeEmpty)
+		})
+
+		c.Convey("没有参数的事件也能格式化", func() {
+			msg, err := fromEnvelope([]byte("<xml><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[click]]></Event><EventKey><![CDATA[k]]></EventKey></xml>"))
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.String(), c.ShouldNotBeEmpty)
+		})
+	})
+}
+
+func TestRxMessageEventChangeContact(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		c.Convey("更新成员事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>update_user</ChangeType><UserID><![CDATA[zhangsan]]></UserID><NewUserID><![CDATA[zhangsan001]]></NewUserID><Name><![CDATA[张三]]></Name><Department><![CDATA[1,2,3]]></Department><MainDepartment>1</MainDepartment><IsLeaderInDept><![CDATA[1,0,0]]></IsLeaderInDept><DirectLeader><![CDATA[lisi,wangwu]]></DirectLeader><Mobile><![CDATA[13800000000]]></Mobile><Gender>1</Gender><Status>1</Status><ExtAttr><Item><Name><![CDATA[爱好]]></Name><Type>0</Type><Text><Value><![CDATA[旅游]]></Value></Text></Item><Item><Name><![CDATA[卡号]]></Name><Type>1</Type><Web><Title><![CDATA[企业微信]]></Title><Url><![CDATA[https://work.weixin.qq.com]]></Url></Web></Item></ExtAttr></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.Event, c.ShouldEqual, EventTypeChangeContact)
+			c.So(msg.ChangeType, c.ShouldEqual, ChangeTypeUpdateUser)
+
+			_, ok := msg.EventChangeContactCreateUser()
+			c.So(ok, c.ShouldBeFalse)
+
+			e, ok := msg.EventChangeContactUpdateUser()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetUserID(), c.ShouldEqual, "zhangsan")
+			c.So(e.GetNewUserID(), c.ShouldEqual, "zhangsan001")
+			c.So(e.GetName(), c.ShouldEqual, "张三")
+			c.So(e.GetDepartments(), c.ShouldResemble, []int64{1, 2, 3})
+			c.So(e.GetMainDepartment(), c.ShouldEqual, 1)
+			c.So(e.GetIsLeaderInDept(), c.ShouldResemble, []bool{true, false, false})
+			c.So(e.GetDirectLeaders(), c.ShouldResemble, []string{"lisi", "wangwu"})
+			c.So(e.GetGender(), c.ShouldEqual, UserGenderMale)
+			c.So(e.GetStatus(), c.ShouldEqual, UserStatusActivated)
+			c.So(e.GetEmail(), c.ShouldBeEmpty)
+			c.So(e.GetExtAttrs(), c.ShouldResemble, []ChangeContactExtAttr{
+				{Name: "爱好", Type: 0, TextValue: "旅游"},
+				{Name: "卡号", Type: 1, WebTitle: "企业微信", WebURL: "https://work.weixin.qq.com"},
+			})
+			c.So(msg.String(), c.ShouldContainSubstring, `NewUserID: "zhangsan001"`)
+		})
+
+		c.Convey("删除成员事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>delete_user</ChangeType><UserID><![CDATA[zhangsan]]></UserID></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			e, ok := msg.EventChangeContactDeleteUser()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetUserID(), c.ShouldEqual, "zhangsan")
+		})
+
+		c.Convey("新增部门事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType>create_party</ChangeType><Id>2</Id><Name><![CDATA[张三]]></Name><ParentId><![CDATA[1]]></ParentId><Order>1</Order></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			_, ok := msg.EventChangeContactDeleteParty()
+			c.So(ok, c.ShouldBeFalse)
+
+			e, ok := msg.EventChangeContactCreateParty()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetID(), c.ShouldEqual, 2)
+			c.So(e.GetName(), c.ShouldEqual, "张三")
+			c.So(e.GetParentID(), c.ShouldEqual, 1)
+			c.So(e.GetOrder(), c.ShouldEqual, 1)
+		})
+
+		c.Convey("标签成员变更事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event><ChangeType><![CDATA[update_tag]]></ChangeType><TagId>1</TagId><AddUserItems><![CDATA[zhangsan,lisi]]></AddUserItems><DelUserItems><![CDATA[zhangsan1]]></DelUserItems><AddPartyItems><![CDATA[1,2]]></AddPartyItems><DelPartyItems><![CDATA[]]></DelPartyItems></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			e, ok := msg.EventChangeContactUpdateTag()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetTagID(), c.ShouldEqual, 1)
+			c.So(e.GetAddUserItems(), c.ShouldResemble, []string{"zhangsan", "lisi"})
+			c.So(e.GetDelUserItems(), c.ShouldResemble, []string{"zhangsan1"})
+			c.So(e.GetAddPartyItems(), c.ShouldResemble, []int64{1, 2})
+			c.So(e.GetDelPartyItems(), c.ShouldBeNil)
+		})
+	})
+}
+
+func TestRxMessageAppEvents(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		parse := func(body string) *RxMessage {
+			msg, err := fromEnvelope([]byte(body))
+			c.So(err, c.ShouldBeNil)
+			c.So(msg, c.ShouldNotBeNil)
+			_, unknown := msg.Unknown()
+			c.So(unknown, c.ShouldBeFalse)
+			return msg
+		}
+
+		c.Convey("点击菜单与进入应用事件", func() {
+			msg := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[click]]></Event><EventKey><![CDATA[EVENTKEY]]></EventKey><AgentID>1</AgentID></xml>")
+			e, ok := msg.EventMenu()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetEventKey(), c.ShouldEqual, "EVENTKEY")
+			c.So(msg.EventKey, c.ShouldEqual, "EVENTKEY")
+
+			msg = parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408091189</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[enter_agent]]></Event><EventKey><![CDATA[]]></EventKey><AgentID>1</AgentID></xml>")
+			c.So(msg.Event, c.ShouldEqual, EventTypeEnterAgent)
+			_, ok = msg.EventMenu()
+			c.So(ok, c.ShouldBeTrue)
+		})
+
+		c.Convey("上报地理位置事件", func() {
+			msg := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[LOCATION]]></Event><Latitude>23.104</Latitude><Longitude>113.320</Longitude><Precision>65.000</Precision><AgentID>1</AgentID><AppType><![CDATA[wxwork]]></AppType></xml>")
+			e, ok := msg.EventLocation()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetLatitude(), c.ShouldEqual, 23.104)
+			c.So(e.GetLongitude(), c.ShouldEqual, 113.320)
+			c.So(e.GetPrecision(), c.ShouldEqual, 65.0)
+		})
+
+		c.Convey("扫码推事件", func() {
+			msg := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408090502</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[scancode_waitmsg]]></Event><EventKey><![CDATA[6]]></EventKey><ScanCodeInfo><ScanType><![CDATA[qrcode]]></ScanType><ScanResult><![CDATA[2]]></ScanResult></ScanCodeInfo><AgentID>1</AgentID></xml>")
+			_, ok := msg.EventMenu()
+			c.So(ok, c.ShouldBeFalse)
+
+			e, ok := msg.EventScancode()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetEventKey(), c.ShouldEqual, "6")
+			c.So(e.GetScanType(), c.ShouldEqual, "qrcode")
+			c.So(e.GetScanResult(), c.ShouldEqual, "2")
+		})
+
+		c.Convey("弹出发图器事件", func() {
+			msg := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408090651</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[pic_photo_or_album]]></Event><EventKey><![CDATA[6]]></EventKey><SendPicsInfo><Count>2</Count><PicList><item><PicMd5Sum><![CDATA[1b5f7c23b5bf75682a53e7b6d163e185]]></PicMd5Sum></item><item><PicMd5Sum><![CDATA[5a75aaca956d97be686719218f275c6b]]></PicMd5Sum></item></PicList></SendPicsInfo><AgentID>1</AgentID></xml>")
+			e, ok := msg.EventPic()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetCount(), c.ShouldEqual, 2)
+			c.So(e.GetPicMd5Sums(), c.ShouldResemble, []string{
+				"1b5f7c23b5bf75682a53e7b6d163e185",
+				"5a75aaca956d97be686719218f275c6b",
+			})
+		})
+
+		c.Convey("弹出地理位置选择器事件", func() {
+			msg := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>1408091189</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[location_select]]></Event><EventKey><![CDATA[6]]></EventKey><SendLocationInfo><Location_X><![CDATA[23]]></Location_X><Location_Y><![CDATA[113]]></Location_Y><Scale><![CDATA[15]]></Scale><Label><![CDATA[ 广州市海珠区客村艺苑路 106号]]></Label><Poiname><![CDATA[]]></Poiname></SendLocationInfo><AgentID>1</AgentID><AppType><![CDATA[wxwork]]></AppType></xml>")
+			e, ok := msg.EventLocationSelect()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetLatitude(), c.ShouldEqual, 23)
+			c.So(e.GetLongitude(), c.ShouldEqual, 113)
+			c.So(e.GetScale(), c.ShouldEqual, 15)
+			c.So(e.GetLabel(), c.ShouldEqual, " 广州市海珠区客村艺苑路 106号")
+			c.So(e.GetPoiName(), c.ShouldBeEmpty)
+
+			_, ok = msg.Location()
+			c.So(ok, c.ShouldBeFalse)
+		})
+
+		c.Convey("异步任务完成事件", func() {
+			msg := parse("<xml><ToUserName><![CDATA[wx28dbb14e3720FAKE]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1425284517</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[batch_job_result]]></Event><BatchJob><JobId><![CDATA[S0MrnndvRG5fadSlLwiBqiDDbM143UqTmKP3152FZk4]]></JobId><JobType><![CDATA[sync_user]]></JobType><ErrCode>0</ErrCode><ErrMsg><![CDATA[ok]]></ErrMsg></BatchJob></xml>")
+			e, ok := msg.EventBatchJobResult()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetJobID(), c.ShouldEqual, "S0MrnndvRG5fadSlLwiBqiDDbM143UqTmKP3152FZk4")
+			c.So(e.GetJobType(), c.ShouldEqual, "sync_user")
+			c.So(e.GetErrCode(), c.ShouldEqual, 0)
+			c.So(e.GetErrMsg(), c.ShouldEqual, "ok")
+		})
+
+		c.Convey("模板卡片事件", func() {
+			msg := parse("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[template_card_event]]></Event><EventKey><![CDATA[key111]]></EventKey><TaskId><![CDATA[taskid111]]></TaskId><CardType><![CDATA[vote_interaction]]></CardType><ResponseCode><![CDATA[ResponseCode]]></ResponseCode><AgentID>1</AgentID><SelectedItems><SelectedItem><QuestionKey><![CDATA[QuestionKey1]]></QuestionKey><OptionIds><OptionId><![CDATA[OptionId1]]></OptionId><OptionId><![CDATA[OptionId2]]></OptionId></OptionIds></SelectedItem></SelectedItems></xml>")
+			e, ok := msg.EventTemplateCardEvent()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetEventKey(), c.ShouldEqual, "key111")
+			c.So(e.GetTaskID(), c.ShouldEqual, "taskid111")
+			c.So(e.GetCardType(), c.ShouldEqual, TemplateCardTypeVoteInteraction)
+			c.So(e.GetResponseCode(), c.ShouldEqual, "ResponseCode")
+			c.So(e.GetSelectedItems(), c.ShouldResemble, []TemplateCardEventSelectedItem{
+				{QuestionKey: "QuestionKey1", OptionIDs: []string{"OptionId1", "OptionId2"}},
+			})
+		})
+	})
+}
+
+func TestRxMessageEventExternalChatAndTag(t *testing.T) {
+	c.Convey("解析接收的 XML 消息体", t, func() {
+		c.Convey("客户群变更事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_chat]]></Event><ChatId><![CDATA[CHAT_ID]]></ChatId><ChangeType><![CDATA[update]]></ChangeType><UpdateDetail><![CDATA[add_member]]></UpdateDetail><JoinScene>1</JoinScene><QuitScene>0</QuitScene><MemChangeCnt>2</MemChangeCnt><MemChangeList><Item>Jack</Item><Item>Rose</Item></MemChangeList></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.ChangeType, c.ShouldEqual, ChangeTypeUpdate)
+
+			e, ok := msg.EventChangeExternalChat()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetChatID(), c.ShouldEqual, "CHAT_ID")
+			c.So(e.GetFromUserName(), c.ShouldEqual, "sys")
+			c.So(e.GetUpdateDetail(), c.ShouldEqual, ExternalChatUpdateDetailAddMember)
+			c.So(e.GetJoinScene(), c.ShouldEqual, ExternalChatJoinSceneInviteLink)
+			c.So(e.GetQuitScene(), c.ShouldEqual, ExternalChatQuitSceneSelf)
+			c.So(e.GetMemChangeCnt(), c.ShouldEqual, 2)
+			c.So(e.GetMemChangeList(), c.ShouldResemble, []string{"Jack", "Rose"})
+		})
+
+		c.Convey("企业客户标签变更事件", func() {
+			body := []byte("<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_external_tag]]></Event><Id><![CDATA[TAG_ID]]></Id><TagType><![CDATA[tag_group]]></TagType><ChangeType><![CDATA[delete]]></ChangeType><StrategyId>1</StrategyId></xml>")
+
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+			c.So(msg.ChangeType, c.ShouldEqual, ChangeTypeDelete)
+
+			_, ok := msg.EventChangeExternalChat()
+			c.So(ok, c.ShouldBeFalse)
+
+			e, ok := msg.EventChangeExternalTag()
+			c.So(ok, c.ShouldBeTrue)
+			c.So(e.GetID(), c.ShouldEqual, "TAG_ID")
+			c.So(e.GetTagType(), c.ShouldEqual, ExternalTagTypeTagGroup)
+			c.So(e.GetStrategyID(), c.ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,141 @@
+package workwx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
+)
+
+// CallbackMux 在同一个 http.Handler 上托管多个应用的回调
+//
+// 每个应用各自使用一个 HTTPHandler（及其 Token、EncodingAESKey 与消息处理器），
+// CallbackMux 按以下顺序为请求选择 HTTPHandler：
+//
+//  1. 请求路径与 HandlePath 注册的路径完全一致；
+//  2. 回调事件请求（POST）明文信封中的 AgentID 与 HandleAgentID 注册的一致；
+//  3. 依注册顺序，第一个能以其 Token 通过签名校验的 HTTPHandler。
+//
+// URL 验证请求（GET）与通讯录回调等不带 AgentID 的请求只能通过路径或签名匹配，
+// 因此通过 AgentID 路由的各应用不应共用 Token。都不匹配时以 400 响应。
+//
+// 所有 HTTPHandler 应在开始处理请求前注册完毕，注册过程不是并发安全的。
+type CallbackMux struct {
+	byPath      map[string]*HTTPHandler
+	byAgentID   map[int64]*HTTPHandler
+	handlers    []*HTTPHandler
+	ipAllowlist *CallbackIPAllowlist
+}
+
+var _ http.Handler = (*CallbackMux)(nil)
+
+type callbackMuxOptions struct {
+	ipAllowlist *CallbackIPAllowlist
+}
+
+// CallbackMuxOption CallbackMux 构造参数
+type CallbackMuxOption interface {
+	applyTo(*callbackMuxOptions)
+}
+
+type withCallbackMuxIPAllowlist struct {
+	x *CallbackIPAllowlist
+}
+
+// WithCallbackMuxIPAllowlist 在路由前拒绝来源不在企业微信回调IP段内的回调请求，以 403 响应
+//
+// 默认不检查回调来源。与给各 HTTPHandler 设置 WithCallbackIPAllowlist 不同，
+// 被拒绝的请求不会被读取请求体或校验签名。
+func WithCallbackMuxIPAllowlist(a *CallbackIPAllowlist) CallbackMuxOption {
+	return &withCallbackMuxIPAllowlist{x: a}
+}
+
+func (x *withCallbackMuxIPAllowlist) applyTo(y *callbackMuxOptions) {
+	y.ipAllowlist = x.x
+}
+
+// NewCallbackMux 构造一个空的 CallbackMux
+func NewCallbackMux(opts ...CallbackMuxOption) *CallbackMux {
+	var options callbackMuxOptions
+	for _, o := range opts {
+		o.applyTo(&options)
+	}
+
+	return &CallbackMux{
+		byPath:      make(map[string]*HTTPHandler),
+		byAgentID:   make(map[int64]*HTTPHandler),
+		ipAllowlist: options.ipAllowlist,
+	}
+}
+
+// HandlePath 将请求路径为 path 的回调交给 h 处理
+func (m *CallbackMux) HandlePath(path string, h *HTTPHandler) {
+	m.byPath[path] = h
+	m.addHandler(h)
+}
+
+// HandleAgentID 将来自应用 agentID 的回调交给 h 处理
+func (m *CallbackMux) HandleAgentID(agentID int64, h *HTTPHandler) {
+	m.byAgentID[agentID] = h
+	m.addHandler(h)
+}
+
+func (m *CallbackMux) addHandler(h *HTTPHandler) {
+	for _, x := range m.handlers {
+		if x == h {
+			return
+		}
+	}
+	m.handlers = append(m.handlers, h)
+}
+
+// ServeHTTP 处理一个回调请求
+func (m *CallbackMux) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if m.ipAllowlist != nil && !m.ipAllowlist.AllowsRequest(r) {
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if h, ok := m.byPath[r.URL.Path]; ok {
+		h.ServeHTTP(rw, r)
+		return
+	}
+
+	var encrypt string
+	if r.Method == http.MethodPost {
+		// request bodies are assumed small, same as in the low-level handler
+		body, err := ioutil.ReadAll(r.Body)
+		_ = r.Body.Close()
+		if err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		hdr, err := envelope.PeekHeader(body)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if agentID, err := strconv.ParseInt(hdr.AgentID, 10, 64); err == nil {
+			if h, ok := m.byAgentID[agentID]; ok {
+				h.ServeHTTP(rw, r)
+				return
+			}
+		}
+
+		encrypt = hdr.Encrypt
+	}
+
+	for _, h := range m.handlers {
+		if h.inner.VerifySignature(r.URL, encrypt) {
+			h.ServeHTTP(rw, r)
+			return
+		}
+	}
+
+	rw.WriteHeader(http.StatusBadRequest)
+}
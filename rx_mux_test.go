@@ -0,0 +1,129 @@
+package workwx
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
+)
+
+func TestCallbackMux(t *testing.T) {
+	c.Convey("多应用回调路由", t, func() {
+		type app struct {
+			token string
+			key   string
+			pr    *envelope.Processor
+		}
+		//nolint: gosec  // randomly generated for test purposes only
+		apps := map[string]*app{
+			"a": {token: "kjr2TKI8umCBfVF3wAHk8JiPwma5VBme", key: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws"},
+			"b": {token: "kz7Yx62CH8SaLN", key: "cD0d7jx4tYvVtzqrmh3Dm3QFCXe6f8SlHoMtMh3qQEP"},
+			"c": {token: "c0ffee", key: "4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws"},
+		}
+
+		var hit string
+		mux := NewCallbackMux()
+		for name, a := range apps {
+			name := name
+			pr, err := envelope.NewProcessor(a.token, a.key)
+			c.So(err, c.ShouldBeNil)
+			a.pr = pr
+
+			h, err := NewHTTPHandler(a.token, a.key, RxMessageHandlerFunc(func(_ context.Context, _ *RxMessage) (RxReply, error) {
+				hit = name
+				return nil, nil
+			}))
+			c.So(err, c.ShouldBeNil)
+
+			switch name {
+			case "a":
+				mux.HandleAgentID(1000001, h)
+			case "b":
+				mux.HandleAgentID(1000002, h)
+			case "c":
+				mux.HandlePath("/c", h)
+			}
+		}
+
+		// signed returns the query string and Encrypt field of an envelope
+		// carrying msg, as produced by the app's credentials.
+		signed := func(a *app, msg string) (url.Values, string) {
+			out, err := a.pr.MakeOutgoingEnvelope([]byte(msg))
+			c.So(err, c.ShouldBeNil)
+
+			var x struct {
+				Encrypt      string `xml:"Encrypt"`
+				MsgSignature string `xml:"MsgSignature"`
+				Timestamp    int64  `xml:"Timestamp"`
+				Nonce        string `xml:"Nonce"`
+			}
+			c.So(xml.Unmarshal(out, &x), c.ShouldBeNil)
+
+			q := url.Values{}
+			q.Set("msg_signature", x.MsgSignature)
+			q.Set("timestamp", fmt.Sprint(x.Timestamp))
+			q.Set("nonce", x.Nonce)
+			return q, x.Encrypt
+		}
+
+		post := func(path string, a *app, agentID string) int {
+			q, encrypt := signed(a, "<xml><MsgType>text</MsgType><Content>hi</Content></xml>")
+			body := fmt.Sprintf(
+				"<xml><ToUserName>ww6a112864f8022910</ToUserName><AgentID>%s</AgentID><Encrypt>%s</Encrypt></xml>",
+				agentID,
+				encrypt,
+			)
+			req := httptest.NewRequest(http.MethodPost, path+"?"+q.Encode(), strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		c.Convey("按 AgentID 路由", func() {
+			c.So(post("/cb", apps["b"], "1000002"), c.ShouldEqual, http.StatusOK)
+			c.So(hit, c.ShouldEqual, "b")
+		})
+
+		c.Convey("没有 AgentID 时按签名路由", func() {
+			c.So(post("/cb", apps["a"], ""), c.ShouldEqual, http.StatusOK)
+			c.So(hit, c.ShouldEqual, "a")
+		})
+
+		c.Convey("按路径路由", func() {
+			c.So(post("/c", apps["c"], "1000001"), c.ShouldEqual, http.StatusOK)
+			c.So(hit, c.ShouldEqual, "c")
+		})
+
+		c.Convey("URL 验证请求按签名路由", func() {
+			q, encrypt := signed(apps["b"], "echo-b")
+			q.Set("echostr", encrypt)
+			req := httptest.NewRequest(http.MethodGet, "/cb?"+q.Encode(), nil)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			c.So(rec.Code, c.ShouldEqual, http.StatusOK)
+			body, err := ioutil.ReadAll(rec.Body)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(body), c.ShouldEqual, "echo-b")
+		})
+
+		c.Convey("无法匹配的请求应该被拒绝", func() {
+			stranger := &app{token: "nobody", key: apps["a"].key}
+			pr, err := envelope.NewProcessor(stranger.token, stranger.key)
+			c.So(err, c.ShouldBeNil)
+			stranger.pr = pr
+
+			c.So(post("/cb", stranger, "1000003"), c.ShouldEqual, http.StatusBadRequest)
+			c.So(hit, c.ShouldBeEmpty)
+		})
+	})
+}
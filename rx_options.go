@@ -0,0 +1,110 @@
+package workwx
+
+import (
+	"time"
+
+	"github.com/xen0n/go-workwx/internal/lowlevel/envelope"
+)
+
+type httpHandlerOptions struct {
+	envelopeOpts []envelope.ProcessorOption
+	ipAllowlist  *CallbackIPAllowlist
+}
+
+// HTTPHandlerOption 回调请求处理器构造参数
+type HTTPHandlerOption interface {
+	applyTo(*httpHandlerOptions)
+}
+
+// CallbackNonceCache 回调请求 nonce 缓存，用于防重放
+//
+// MarkIfAbsent 记录 key 并保留 ttl 时长，返回调用前该 key 是否不存在（或已过期）；
+// Forget 删除 key 的记录，在回调处理失败时调用，以便企业微信的重试请求不被当作重放拒绝。
+// 可自行实现（如基于 Redis）以在多个处理器、多个进程间共享。
+type CallbackNonceCache = envelope.NonceCache
+
+// NewMemoryNonceCache 构造一个最多容纳 capacity 条记录的内存 nonce 缓存
+//
+// 缓存已满时，最早记录的 nonce 会被优先淘汰。
+func NewMemoryNonceCache(capacity int) CallbackNonceCache {
+	return envelope.NewMemoryNonceCache(capacity)
+}
+
+// ErrCallbackStaleTimestamp 回调请求的时间戳超出了允许的时间窗口
+var ErrCallbackStaleTimestamp = envelope.ErrStaleTimestamp
+
+// ErrCallbackDuplicateNonce 回调请求的 nonce 已出现过，很可能是重放请求
+var ErrCallbackDuplicateNonce = envelope.ErrDuplicateNonce
+
+// CallbackReceiveIDMismatchError 回调消息解密后的 ReceiveID 与预期不符
+//
+// 通常意味着消息是发给其他企业或第三方应用的，只是恰好共用了 EncodingAESKey。
+type CallbackReceiveIDMismatchError = envelope.ReceiveIDMismatchError
+
+//
+//
+//
+
+type withCallbackTimestampWindow struct {
+	x time.Duration
+}
+
+// WithCallbackTimestampWindow 拒绝时间戳与当前时间相差超过 d 的回调请求
+//
+// 默认不检查时间戳。被拒绝的请求以 403 响应。
+func WithCallbackTimestampWindow(d time.Duration) HTTPHandlerOption {
+	return &withCallbackTimestampWindow{x: d}
+}
+
+var _ HTTPHandlerOption = (*withCallbackTimestampWindow)(nil)
+
+func (x *withCallbackTimestampWindow) applyTo(y *httpHandlerOptions) {
+	y.envelopeOpts = append(y.envelopeOpts, envelope.WithTimestampWindow(x.x))
+}
+
+//
+//
+//
+
+type withCallbackNonceCache struct {
+	x CallbackNonceCache
+}
+
+// WithCallbackNonceCache 拒绝 nonce 已出现过的回调请求
+//
+// 默认不检查 nonce。建议与 WithCallbackTimestampWindow 一同使用，此时 nonce 只需
+// 保留到时间窗口结束即可。被拒绝的请求以 409 响应。
+//
+// nonce 在回调处理成功后才算用掉：处理出错时记录会被删除，企业微信以相同
+// timestamp 与 nonce 发起的重试仍会被接受。
+func WithCallbackNonceCache(c CallbackNonceCache) HTTPHandlerOption {
+	return &withCallbackNonceCache{x: c}
+}
+
+var _ HTTPHandlerOption = (*withCallbackNonceCache)(nil)
+
+func (x *withCallbackNonceCache) applyTo(y *httpHandlerOptions) {
+	y.envelopeOpts = append(y.envelopeOpts, envelope.WithNonceCache(x.x))
+}
+
+//
+//
+//
+
+type withCallbackReceiveIDs struct {
+	x []string
+}
+
+// WithCallbackReceiveIDs 拒绝解密后 ReceiveID 不在 ids 之中的回调请求
+//
+// 自建应用的 ReceiveID 为 CorpID，第三方应用的指令回调则为 SuiteID。不匹配的
+// 回调请求会以 400 响应，URL 验证请求同理。默认不检查 ReceiveID。
+func WithCallbackReceiveIDs(ids ...string) HTTPHandlerOption {
+	return &withCallbackReceiveIDs{x: ids}
+}
+
+var _ HTTPHandlerOption = (*withCallbackReceiveIDs)(nil)
+
+func (x *withCallbackReceiveIDs) applyTo(y *httpHandlerOptions) {
+	y.envelopeOpts = append(y.envelopeOpts, envelope.WithReceiveIDs(x.x...))
+}
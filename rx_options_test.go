@@ -0,0 +1,36 @@
+package workwx
+
+import (
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHTTPHandlerOptions(t *testing.T) {
+	c.Convey("给定一个 httpHandlerOptions", t, func() {
+		opts := httpHandlerOptions{}
+
+		c.Convey("用防重放参数修饰它", func() {
+			WithCallbackTimestampWindow(5 * time.Minute).applyTo(&opts)
+			WithCallbackNonceCache(NewMemoryNonceCache(128)).applyTo(&opts)
+			WithCallbackReceiveIDs("ww6a112864f8022910").applyTo(&opts)
+
+			c.Convey("应该传递给底层的 envelope.Processor", func() {
+				c.So(len(opts.envelopeOpts), c.ShouldEqual, 3)
+			})
+		})
+
+		c.Convey("构造 HTTPHandler 时应该接受这些参数", func() {
+			h, err := NewHTTPHandler(
+				"kjr2TKI8umCBfVF3wAHk8JiPwma5VBme",
+				"4Ma3YBrSBbX2aez8MJpXGBne5LSDwgGqHbhM9WPYIws",
+				RxMessageHandlerFunc(nil),
+				WithCallbackTimestampWindow(5*time.Minute),
+				WithCallbackNonceCache(NewMemoryNonceCache(128)),
+			)
+			c.So(err, c.ShouldBeNil)
+			c.So(h, c.ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,204 @@
+package workwx
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// RxReply 被动回复消息
+//
+// 在 RxMessageHandler.OnIncomingMessage 中返回，SDK 会将其加密、签名后作为回调
+// 请求的响应发出，省去一次主动发送消息的接口调用。本接口仅由本包内的类型实现，
+// 如 TextReply、NewsReply 等。
+type RxReply interface {
+	// intoReplyXML 组装回复给 msg 发送者的明文 XML
+	intoReplyXML(msg *RxMessage, now time.Time) ([]byte, error)
+}
+
+// MarshalRxReply 组装回复给 msg 发送者的被动回复明文 XML，now 为回复时间
+//
+// 供自行处理回调请求的场景使用，返回值可交由 callbackcrypto.Crypto.EncryptReply
+// 加密；使用 HTTPHandler 时 SDK 会自动完成这一步。
+func MarshalRxReply(reply RxReply, msg *RxMessage, now time.Time) ([]byte, error) {
+	return reply.intoReplyXML(msg, now)
+}
+
+type rxReplyCData struct {
+	Value string `xml:",cdata"`
+}
+
+// rxReplyCommon 被动回复消息的公共部分
+type rxReplyCommon struct {
+	XMLName      xml.Name     `xml:"xml"`
+	ToUserName   rxReplyCData `xml:"ToUserName"`
+	FromUserName rxReplyCData `xml:"FromUserName"`
+	CreateTime   int64        `xml:"CreateTime"`
+	MsgType      rxReplyCData `xml:"MsgType"`
+}
+
+func makeRxReplyCommon(msg *RxMessage, now time.Time, msgtype string) rxReplyCommon {
+	return rxReplyCommon{
+		// 被动回复的收发双方与接收到的消息相反
+		ToUserName:   rxReplyCData{msg.FromUserID},
+		FromUserName: rxReplyCData{msg.CorpID},
+		CreateTime:   now.Unix(),
+		MsgType:      rxReplyCData{msgtype},
+	}
+}
+
+type rxReplyMedia struct {
+	MediaID rxReplyCData `xml:"MediaId"`
+}
+
+// TextReply 被动回复文本消息
+type TextReply struct {
+	// Content 文本消息内容，最长不超过2048个字节，超过将截断
+	Content string
+}
+
+var _ RxReply = TextReply{}
+
+func (r TextReply) intoReplyXML(msg *RxMessage, now time.Time) ([]byte, error) {
+	return xml.Marshal(struct {
+		rxReplyCommon
+		Content rxReplyCData `xml:"Content"`
+	}{
+		rxReplyCommon: makeRxReplyCommon(msg, now, "text"),
+		Content:       rxReplyCData{r.Content},
+	})
+}
+
+// ImageReply 被动回复图片消息
+type ImageReply struct {
+	// MediaID 图片文件id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ RxReply = ImageReply{}
+
+func (r ImageReply) intoReplyXML(msg *RxMessage, now time.Time) ([]byte, error) {
+	return xml.Marshal(struct {
+		rxReplyCommon
+		Image rxReplyMedia `xml:"Image"`
+	}{
+		rxReplyCommon: makeRxReplyCommon(msg, now, "image"),
+		Image:         rxReplyMedia{MediaID: rxReplyCData{r.MediaID}},
+	})
+}
+
+// VoiceReply 被动回复语音消息
+type VoiceReply struct {
+	// MediaID 语音文件id，可以调用上传临时素材接口获取
+	MediaID string
+}
+
+var _ RxReply = VoiceReply{}
+
+func (r VoiceReply) intoReplyXML(msg *RxMessage, now time.Time) ([]byte, error) {
+	return xml.Marshal(struct {
+		rxReplyCommon
+		Voice rxReplyMedia `xml:"Voice"`
+	}{
+		rxReplyCommon: makeRxReplyCommon(msg, now, "voice"),
+		Voice:         rxReplyMedia{MediaID: rxReplyCData{r.MediaID}},
+	})
+}
+
+// VideoReply 被动回复视频消息
+type VideoReply struct {
+	// MediaID 视频文件id，可以调用上传临时素材接口获取
+	MediaID string
+	// Title 视频消息的标题，不超过128个字节，超过会自动截断
+	Title string
+	// Description 视频消息的描述，不超过512个字节，超过会自动截断
+	Description string
+}
+
+var _ RxReply = VideoReply{}
+
+func (r VideoReply) intoReplyXML(msg *RxMessage, now time.Time) ([]byte, error) {
+	type video struct {
+		MediaID     rxReplyCData `xml:"MediaId"`
+		Title       rxReplyCData `xml:"Title"`
+		Description rxReplyCData `xml:"Description"`
+	}
+
+	return xml.Marshal(struct {
+		rxReplyCommon
+		Video video `xml:"Video"`
+	}{
+		rxReplyCommon: makeRxReplyCommon(msg, now, "video"),
+		Video: video{
+			MediaID:     rxReplyCData{r.MediaID},
+			Title:       rxReplyCData{r.Title},
+			Description: rxReplyCData{r.Description},
+		},
+	})
+}
+
+// NewsReply 被动回复图文消息
+type NewsReply struct {
+	// Articles 图文列表，支持1到8篇图文
+	//
+	// 被动回复不支持小程序图文，各篇图文的 AppID、PagePath 字段会被忽略。
+	Articles []NewsArticle
+}
+
+var _ RxReply = NewsReply{}
+
+func (r NewsReply) intoReplyXML(msg *RxMessage, now time.Time) ([]byte, error) {
+	if err := validateNewsArticles(r.Articles); err != nil {
+		return nil, err
+	}
+
+	type item struct {
+		Title       rxReplyCData `xml:"Title"`
+		Description rxReplyCData `xml:"Description"`
+		PicURL      rxReplyCData `xml:"PicUrl"`
+		URL         rxReplyCData `xml:"Url"`
+	}
+
+	items := make([]item, len(r.Articles))
+	for i, a := range r.Articles {
+		items[i] = item{
+			Title:       rxReplyCData{a.Title},
+			Description: rxReplyCData{a.Description},
+			PicURL:      rxReplyCData{a.PicURL},
+			URL:         rxReplyCData{a.URL},
+		}
+	}
+
+	return xml.Marshal(struct {
+		rxReplyCommon
+		ArticleCount int    `xml:"ArticleCount"`
+		Articles     []item `xml:"Articles>item"`
+	}{
+		rxReplyCommon: makeRxReplyCommon(msg, now, "news"),
+		ArticleCount:  len(items),
+		Articles:      items,
+	})
+}
+
+// UpdateButtonReply 被动回复更新模板卡片按钮
+//
+// 仅可用于回复模板卡片按钮点击事件，将被点击的按钮替换为不可点击的文案。
+type UpdateButtonReply struct {
+	// ReplaceName 点击后按钮替换的名称
+	ReplaceName string
+}
+
+var _ RxReply = UpdateButtonReply{}
+
+func (r UpdateButtonReply) intoReplyXML(msg *RxMessage, now time.Time) ([]byte, error) {
+	type button struct {
+		ReplaceName rxReplyCData `xml:"ReplaceName"`
+	}
+
+	return xml.Marshal(struct {
+		rxReplyCommon
+		Button button `xml:"Button"`
+	}{
+		rxReplyCommon: makeRxReplyCommon(msg, now, "update_button"),
+		Button:        button{ReplaceName: rxReplyCData{r.ReplaceName}},
+	})
+}
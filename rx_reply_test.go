@@ -0,0 +1,58 @@
+package workwx
+
+import (
+	"testing"
+	"time"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxReply(t *testing.T) {
+	c.Convey("组装被动回复消息", t, func() {
+		msg := &RxMessage{
+			CorpID:     "ww6a112864f8022910",
+			FromUserID: "foobar",
+		}
+		now := time.Unix(1583995625, 0)
+
+		c.Convey("文本消息", func() {
+			out, err := TextReply{Content: "hi"}.intoReplyXML(msg, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hi]]></Content></xml>")
+		})
+
+		c.Convey("图片消息", func() {
+			out, err := ImageReply{MediaID: "mid"}.intoReplyXML(msg, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[image]]></MsgType><Image><MediaId><![CDATA[mid]]></MediaId></Image></xml>")
+		})
+
+		c.Convey("图文消息", func() {
+			out, err := NewsReply{Articles: []NewsArticle{
+				{Title: "t", Description: "d", URL: "https://example.com", PicURL: "https://example.com/a.png"},
+			}}.intoReplyXML(msg, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[news]]></MsgType><ArticleCount>1</ArticleCount><Articles><item><Title><![CDATA[t]]></Title><Description><![CDATA[d]]></Description><PicUrl><![CDATA[https://example.com/a.png]]></PicUrl><Url><![CDATA[https://example.com]]></Url></item></Articles></xml>")
+		})
+
+		c.Convey("为解析出的消息组装回复", func() {
+			parsed, err := ParseRxMessage([]byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>"))
+			c.So(err, c.ShouldBeNil)
+
+			out, err := MarshalRxReply(TextReply{Content: "hi"}, parsed, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hi]]></Content></xml>")
+		})
+
+		c.Convey("不合法的图文消息应该报错", func() {
+			_, err := NewsReply{}.intoReplyXML(msg, now)
+			c.So(err, c.ShouldNotBeNil)
+		})
+
+		c.Convey("更新模板卡片按钮", func() {
+			out, err := UpdateButtonReply{ReplaceName: "已处理"}.intoReplyXML(msg, now)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(out), c.ShouldEqual, "<xml><ToUserName><![CDATA[foobar]]></ToUserName><FromUserName><![CDATA[ww6a112864f8022910]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[update_button]]></MsgType><Button><ReplaceName><![CDATA[已处理]]></ReplaceName></Button></xml>")
+		})
+	})
+}
@@ -0,0 +1,301 @@
+package workwx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RxMiddleware 回调处理中间件，包装一个 RxMessageHandler 并返回新的处理器
+type RxMiddleware func(next RxMessageHandler) RxMessageHandler
+
+func applyRxMiddlewares(h RxMessageHandler, mws []RxMiddleware) RxMessageHandler {
+	// 先注册的中间件位于最外层
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+type rxChangeRouteKey struct {
+	event      EventType
+	changeType ChangeType
+}
+
+// RxRouter 按消息类型、事件类型与变更类型分发回调消息
+//
+// 匹配时依次尝试 Event + ChangeType、Event、MsgType 三级路由，都不匹配时调用
+// Fallback 注册的处理器；未注册 Fallback 时直接以空响应确认。
+//
+// 本类型实现了 RxMessageHandler，可直接传给 NewHTTPHandler。路由应在开始处理
+// 回调前注册完毕，注册过程不是并发安全的。
+type RxRouter struct {
+	middlewares  []RxMiddleware
+	msgRoutes    map[MessageType]RxMessageHandler
+	eventRoutes  map[EventType]RxMessageHandler
+	changeRoutes map[rxChangeRouteKey]RxMessageHandler
+	fallback     RxMessageHandler
+
+	// chain 为包装了全部中间件的分发逻辑，在首条消息到来时构建
+	chainOnce sync.Once
+	chain     RxMessageHandler
+}
+
+var _ RxMessageHandler = (*RxRouter)(nil)
+
+// NewRxRouter 构造一个空的回调路由
+func NewRxRouter() *RxRouter {
+	return &RxRouter{
+		msgRoutes:    make(map[MessageType]RxMessageHandler),
+		eventRoutes:  make(map[EventType]RxMessageHandler),
+		changeRoutes: make(map[rxChangeRouteKey]RxMessageHandler),
+	}
+}
+
+// Use 注册对所有消息生效的中间件，包括 Fallback 处理的与没有路由匹配的消息
+//
+// 中间件在首条消息到来时组装，此后再调用 Use 不会生效。
+func (r *RxRouter) Use(mws ...RxMiddleware) {
+	r.middlewares = append(r.middlewares, mws...)
+}
+
+// OnMessage 注册某类消息的处理器
+func (r *RxRouter) OnMessage(msgType MessageType, h RxMessageHandlerFunc, mws ...RxMiddleware) {
+	r.msgRoutes[msgType] = applyRxMiddlewares(h, mws)
+}
+
+// OnEvent 注册某类事件的处理器
+func (r *RxRouter) OnEvent(event EventType, h RxMessageHandlerFunc, mws ...RxMiddleware) {
+	r.eventRoutes[event] = applyRxMiddlewares(h, mws)
+}
+
+// OnChange 注册某类事件下某种变更类型的处理器
+func (r *RxRouter) OnChange(event EventType, changeType ChangeType, h RxMessageHandlerFunc, mws ...RxMiddleware) {
+	key := rxChangeRouteKey{event: event, changeType: changeType}
+	r.changeRoutes[key] = applyRxMiddlewares(h, mws)
+}
+
+// OnExternalContactChange 注册企业客户事件下某种变更类型的处理器
+func (r *RxRouter) OnExternalContactChange(changeType ChangeType, h RxMessageHandlerFunc, mws ...RxMiddleware) {
+	r.OnChange(EventTypeChangeExternalContact, changeType, h, mws...)
+}
+
+// Fallback 注册没有路由匹配时的处理器
+func (r *RxRouter) Fallback(h RxMessageHandlerFunc, mws ...RxMiddleware) {
+	r.fallback = applyRxMiddlewares(h, mws)
+}
+
+// OnText 注册文本消息的处理器
+func (r *RxRouter) OnText(
+	h func(ctx context.Context, msg *RxMessage, extras TextMessageExtras) (RxReply, error),
+	mws ...RxMiddleware,
+) {
+	r.OnMessage(MessageTypeText, func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+		extras, ok := msg.Text()
+		if !ok {
+			return nil, errRxExtrasMismatch(msg)
+		}
+		return h(ctx, msg, extras)
+	}, mws...)
+}
+
+// OnImage 注册图片消息的处理器
+func (r *RxRouter) OnImage(
+	h func(ctx context.Context, msg *RxMessage, extras ImageMessageExtras) (RxReply, error),
+	mws ...RxMiddleware,
+) {
+	r.OnMessage(MessageTypeImage, func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+		extras, ok := msg.Image()
+		if !ok {
+			return nil, errRxExtrasMismatch(msg)
+		}
+		return h(ctx, msg, extras)
+	}, mws...)
+}
+
+// OnVoice 注册语音消息的处理器
+func (r *RxRouter) OnVoice(
+	h func(ctx context.Context, msg *RxMessage, extras VoiceMessageExtras) (RxReply, error),
+	mws ...RxMiddleware,
+) {
+	r.OnMessage(MessageTypeVoice, func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+		extras, ok := msg.Voice()
+		if !ok {
+			return nil, errRxExtrasMismatch(msg)
+		}
+		return h(ctx, msg, extras)
+	}, mws...)
+}
+
+// OnVideo 注册视频消息的处理器
+func (r *RxRouter) OnVideo(
+	h func(ctx context.Context, msg *RxMessage, extras VideoMessageExtras) (RxReply, error),
+	mws ...RxMiddleware,
+) {
+	r.OnMessage(MessageTypeVideo, func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+		extras, ok := msg.Video()
+		if !ok {
+			return nil, errRxExtrasMismatch(msg)
+		}
+		return h(ctx, msg, extras)
+	}, mws...)
+}
+
+// OnLocation 注册位置消息的处理器
+func (r *RxRouter) OnLocation(
+	h func(ctx context.Context, msg *RxMessage, extras LocationMessageExtras) (RxReply, error),
+	mws ...RxMiddleware,
+) {
+	r.OnMessage(MessageTypeLocation, func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+		extras, ok := msg.Location()
+		if !ok {
+			return nil, errRxExtrasMismatch(msg)
+		}
+		return h(ctx, msg, extras)
+	}, mws...)
+}
+
+// OnLink 注册链接消息的处理器
+func (r *RxRouter) OnLink(
+	h func(ctx context.Context, msg *RxMessage, extras LinkMessageExtras) (RxReply, error),
+	mws ...RxMiddleware,
+) {
+	r.OnMessage(MessageTypeLink, func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+		extras, ok := msg.Link()
+		if !ok {
+			return nil, errRxExtrasMismatch(msg)
+		}
+		return h(ctx, msg, extras)
+	}, mws...)
+}
+
+// OnSysApprovalChange 注册审批申请状态变化回调通知的处理器
+func (r *RxRouter) OnSysApprovalChange(
+	h func(ctx context.Context, msg *RxMessage, extras EventSysApprovalChange) (RxReply, error),
+	mws ...RxMiddleware,
+) {
+	r.OnEvent(EventTypeSysApprovalChange, func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+		extras, ok := msg.EventSysApprovalChange()
+		if !ok {
+			return nil, errRxExtrasMismatch(msg)
+		}
+		return h(ctx, msg, extras)
+	}, mws...)
+}
+
+func errRxExtrasMismatch(msg *RxMessage) error {
+	return fmt.Errorf(
+		"unexpected extras for message: MsgType=%s Event=%s ChangeType=%s",
+		msg.MsgType,
+		msg.Event,
+		msg.ChangeType,
+	)
+}
+
+// OnIncomingMessage 一条消息到来时的回调。
+func (r *RxRouter) OnIncomingMessage(ctx context.Context, msg *RxMessage) (RxReply, error) {
+	r.chainOnce.Do(func() {
+		r.chain = applyRxMiddlewares(RxMessageHandlerFunc(r.dispatch), r.middlewares)
+	})
+	return r.chain.OnIncomingMessage(ctx, msg)
+}
+
+func (r *RxRouter) dispatch(ctx context.Context, msg *RxMessage) (RxReply, error) {
+	h := r.route(msg)
+	if h == nil {
+		// 没有路由匹配，以空响应确认
+		return nil, nil
+	}
+	return h.OnIncomingMessage(ctx, msg)
+}
+
+func (r *RxRouter) route(msg *RxMessage) RxMessageHandler {
+	if msg.MsgType == MessageTypeEvent {
+		if msg.ChangeType != "" {
+			key := rxChangeRouteKey{event: msg.Event, changeType: msg.ChangeType}
+			if h, ok := r.changeRoutes[key]; ok {
+				return h
+			}
+		}
+		if h, ok := r.eventRoutes[msg.Event]; ok {
+			return h
+		}
+	}
+	if h, ok := r.msgRoutes[msg.MsgType]; ok {
+		return h
+	}
+	return r.fallback
+}
+
+//
+// 内置中间件
+//
+
+// RxLoggingMiddleware 以 logrus 记录每条回调消息的处理结果与耗时
+func RxLoggingMiddleware() RxMiddleware {
+	return func(next RxMessageHandler) RxMessageHandler {
+		return RxMessageHandlerFunc(func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+			start := time.Now()
+			reply, err := next.OnIncomingMessage(ctx, msg)
+			entry := logrus.WithFields(logrus.Fields{
+				"msgType":    msg.MsgType,
+				"event":      msg.Event,
+				"changeType": msg.ChangeType,
+				"msgID":      msg.MsgID,
+				"agentID":    msg.AgentID,
+				"from":       msg.FromUserID,
+				"elapsed":    time.Since(start),
+			})
+			if err != nil {
+				entry.Errorf("rx message handling failed: %+v", err)
+			} else {
+				entry.Info("rx message handled")
+			}
+			return reply, err
+		})
+	}
+}
+
+// RxRecoveryMiddleware 将处理器中的 panic 转换为错误，回调请求会以 500 响应
+func RxRecoveryMiddleware() RxMiddleware {
+	return func(next RxMessageHandler) RxMessageHandler {
+		return RxMessageHandlerFunc(func(ctx context.Context, msg *RxMessage) (reply RxReply, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					reply = nil
+					err = fmt.Errorf("panic in rx handler: %v", r)
+				}
+			}()
+			return next.OnIncomingMessage(ctx, msg)
+		})
+	}
+}
+
+// ErrRxAgentIDNotAllowed 回调消息的 AgentID 不在允许范围内，HTTPHandler 会以 403 响应
+var ErrRxAgentIDNotAllowed error = &rxStatusError{
+	msg:        "rx message from disallowed agent",
+	statusCode: http.StatusForbidden,
+}
+
+// RxAgentIDMiddleware 只允许来自指定 AgentID 的回调消息
+//
+// 其他消息不会交给后续处理器，而是返回 ErrRxAgentIDNotAllowed。
+func RxAgentIDMiddleware(agentIDs ...int64) RxMiddleware {
+	allowed := make(map[int64]struct{}, len(agentIDs))
+	for _, id := range agentIDs {
+		allowed[id] = struct{}{}
+	}
+
+	return func(next RxMessageHandler) RxMessageHandler {
+		return RxMessageHandlerFunc(func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+			if _, ok := allowed[msg.AgentID]; !ok {
+				return nil, ErrRxAgentIDNotAllowed
+			}
+			return next.OnIncomingMessage(ctx, msg)
+		})
+	}
+}
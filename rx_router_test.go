@@ -0,0 +1,130 @@
+package workwx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxRouter(t *testing.T) {
+	c.Convey("按类型分发回调消息", t, func() {
+		ctx := context.Background()
+		r := NewRxRouter()
+		var hits []string
+
+		record := func(name string) RxMessageHandlerFunc {
+			return func(_ context.Context, _ *RxMessage) (RxReply, error) {
+				hits = append(hits, name)
+				return nil, nil
+			}
+		}
+
+		textMsg, err := fromEnvelope([]byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>"))
+		c.So(err, c.ShouldBeNil)
+
+		c.Convey("带类型的消息处理器应该拿到相应参数", func() {
+			r.OnText(func(_ context.Context, _ *RxMessage, extras TextMessageExtras) (RxReply, error) {
+				return TextReply{Content: "echo " + extras.GetContent()}, nil
+			})
+
+			reply, err := r.OnIncomingMessage(ctx, textMsg)
+			c.So(err, c.ShouldBeNil)
+			c.So(reply, c.ShouldResemble, TextReply{Content: "echo x123"})
+		})
+
+		c.Convey("路由优先级与 Fallback", func() {
+			r.OnEvent(EventTypeChangeExternalContact, record("event"))
+			r.OnExternalContactChange(ChangeTypeDelFollowUser, record("change"))
+			r.OnMessage(MessageTypeEvent, record("msgtype"))
+
+			ev := func(event EventType, changeType ChangeType) *RxMessage {
+				return &RxMessage{MsgType: MessageTypeEvent, Event: event, ChangeType: changeType}
+			}
+
+			_, _ = r.OnIncomingMessage(ctx, ev(EventTypeChangeExternalContact, ChangeTypeDelFollowUser))
+			_, _ = r.OnIncomingMessage(ctx, ev(EventTypeChangeExternalContact, ChangeTypeAddExternalContact))
+			_, _ = r.OnIncomingMessage(ctx, ev(EventTypeSysApprovalChange, ""))
+			c.So(hits, c.ShouldResemble, []string{"change", "event", "msgtype"})
+
+			reply, err := r.OnIncomingMessage(ctx, textMsg)
+			c.So(err, c.ShouldBeNil)
+			c.So(reply, c.ShouldBeNil)
+			c.So(hits, c.ShouldHaveLength, 3)
+
+			r.Fallback(record("fallback"))
+			_, _ = r.OnIncomingMessage(ctx, textMsg)
+			c.So(hits, c.ShouldResemble, []string{"change", "event", "msgtype", "fallback"})
+		})
+
+		c.Convey("中间件按注册顺序由外向内执行", func() {
+			mw := func(name string) RxMiddleware {
+				return func(next RxMessageHandler) RxMessageHandler {
+					return RxMessageHandlerFunc(func(ctx context.Context, msg *RxMessage) (RxReply, error) {
+						hits = append(hits, name)
+						return next.OnIncomingMessage(ctx, msg)
+					})
+				}
+			}
+			r.Use(mw("global1"), mw("global2"))
+			r.OnMessage(MessageTypeText, record("handler"), mw("route"))
+
+			_, _ = r.OnIncomingMessage(ctx, textMsg)
+			c.So(hits, c.ShouldResemble, []string{"global1", "global2", "route", "handler"})
+		})
+
+		c.Convey("内置中间件", func() {
+			r.Use(RxLoggingMiddleware(), RxRecoveryMiddleware())
+
+			c.Convey("panic 应该被转换为错误", func() {
+				r.OnMessage(MessageTypeText, func(_ context.Context, _ *RxMessage) (RxReply, error) {
+					panic("oops")
+				})
+
+				reply, err := r.OnIncomingMessage(ctx, textMsg)
+				c.So(reply, c.ShouldBeNil)
+				c.So(err, c.ShouldNotBeNil)
+				c.So(err.Error(), c.ShouldContainSubstring, "oops")
+			})
+
+			c.Convey("按 AgentID 鉴权", func() {
+				r.OnMessage(MessageTypeText, record("allowed"), RxAgentIDMiddleware(1000002))
+				r.OnEvent(EventTypeSysApprovalChange, record("denied"), RxAgentIDMiddleware(1000003))
+
+				_, err := r.OnIncomingMessage(ctx, textMsg)
+				c.So(err, c.ShouldBeNil)
+
+				_, err = r.OnIncomingMessage(ctx, &RxMessage{
+					MsgType: MessageTypeEvent,
+					Event:   EventTypeSysApprovalChange,
+					AgentID: 1000002,
+				})
+				c.So(errors.Is(err, ErrRxAgentIDNotAllowed), c.ShouldBeTrue)
+				c.So(statusCodeOf(err), c.ShouldEqual, 403)
+				c.So(hits, c.ShouldResemble, []string{"allowed"})
+			})
+		})
+
+		c.Convey("全局中间件对没有路由匹配的消息也生效", func() {
+			r.Use(RxAgentIDMiddleware(1000003))
+
+			_, err := r.OnIncomingMessage(ctx, textMsg)
+			c.So(errors.Is(err, ErrRxAgentIDNotAllowed), c.ShouldBeTrue)
+			c.So(statusCodeOf(err), c.ShouldEqual, 403)
+
+			reply, err := r.OnIncomingMessage(ctx, &RxMessage{MsgType: MessageTypeText, AgentID: 1000003})
+			c.So(err, c.ShouldBeNil)
+			c.So(reply, c.ShouldBeNil)
+			c.So(hits, c.ShouldBeEmpty)
+		})
+	})
+}
+
+func statusCodeOf(err error) int {
+	var sc interface{ HTTPStatusCode() int }
+	if errors.As(err, &sc) {
+		return sc.HTTPStatusCode()
+	}
+	return 0
+}
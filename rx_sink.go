@@ -0,0 +1,490 @@
+package workwx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RxSink 回调消息的投递目标
+//
+// 配合 NewRxFanoutHandler 使用，可将收到的回调消息原样转发给其他系统。
+type RxSink interface {
+	// Consume 投递一条消息
+	Consume(ctx context.Context, msg *RxMessage) error
+}
+
+// RxSinkFunc 将普通函数适配为 RxSink
+type RxSinkFunc func(ctx context.Context, msg *RxMessage) error
+
+var _ RxSink = RxSinkFunc(nil)
+
+// Consume 投递一条消息
+func (f RxSinkFunc) Consume(ctx context.Context, msg *RxMessage) error {
+	return f(ctx, msg)
+}
+
+// RxFanoutError 部分 RxSink 投递失败
+type RxFanoutError struct {
+	// Errors 各 RxSink 的投递错误，投递成功的为 nil，顺序与构造时传入的 sinks 一致
+	Errors []error
+}
+
+var _ error = (*RxFanoutError)(nil)
+
+func (e *RxFanoutError) Error() string {
+	var l []string
+	for i, err := range e.Errors {
+		if err != nil {
+			l = append(l, fmt.Sprintf("sink #%d: %v", i, err))
+		}
+	}
+	return "rx fanout failed: " + strings.Join(l, "; ")
+}
+
+type rxFanoutHandler struct {
+	sinks []RxSink
+}
+
+var _ RxMessageHandler = (*rxFanoutHandler)(nil)
+
+// NewRxFanoutHandler 构造一个将每条消息依次投递给所有 sinks 的回调消息处理器
+//
+// 某个 RxSink 失败不影响其余 RxSink 的投递；只要有一个失败，即返回 *RxFanoutError，
+// HTTPHandler 会以 500 响应，企业微信稍后会重试。如不希望投递影响回调响应，
+// 可配合 NewRxAsyncHandler 使用。本处理器从不被动回复。
+func NewRxFanoutHandler(sinks ...RxSink) RxMessageHandler {
+	return &rxFanoutHandler{sinks: sinks}
+}
+
+func (h *rxFanoutHandler) OnIncomingMessage(ctx context.Context, msg *RxMessage) (RxReply, error) {
+	var errs []error
+	failed := false
+	for _, s := range h.sinks {
+		err := s.Consume(ctx, msg)
+		if err != nil {
+			failed = true
+		}
+		errs = append(errs, err)
+	}
+
+	if failed {
+		return nil, &RxFanoutError{Errors: errs}
+	}
+	return nil, nil
+}
+
+//
+//
+//
+
+type rxChanSink struct {
+	ch chan<- *RxMessage
+}
+
+var _ RxSink = (*rxChanSink)(nil)
+
+// NewRxChanSink 构造一个将消息发送到 ch 的 RxSink
+//
+// ch 已满时会阻塞，直到 ch 空出位置或 ctx 被取消。
+func NewRxChanSink(ch chan<- *RxMessage) RxSink {
+	return &rxChanSink{ch: ch}
+}
+
+func (s *rxChanSink) Consume(ctx context.Context, msg *RxMessage) error {
+	select {
+	case s.ch <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//
+//
+//
+
+type rxJSONLSinkOptions struct {
+	maxBytes   int64
+	maxBackups int
+}
+
+// RxJSONLSinkOption JSONL 文件 RxSink 构造参数
+type RxJSONLSinkOption interface {
+	applyTo(*rxJSONLSinkOptions)
+}
+
+type withRxJSONLMaxBytes struct {
+	x int64
+}
+
+// WithRxJSONLMaxBytes 文件写入超过 n 字节后轮转，n <= 0 表示不轮转
+//
+// 默认为 100 MiB。
+func WithRxJSONLMaxBytes(n int64) RxJSONLSinkOption {
+	return &withRxJSONLMaxBytes{x: n}
+}
+
+func (x *withRxJSONLMaxBytes) applyTo(y *rxJSONLSinkOptions) {
+	y.maxBytes = x.x
+}
+
+type withRxJSONLMaxBackups struct {
+	x int
+}
+
+// WithRxJSONLMaxBackups 轮转后最多保留 n 个旧文件，n <= 0 表示全部保留
+//
+// 默认全部保留。
+func WithRxJSONLMaxBackups(n int) RxJSONLSinkOption {
+	return &withRxJSONLMaxBackups{x: n}
+}
+
+func (x *withRxJSONLMaxBackups) applyTo(y *rxJSONLSinkOptions) {
+	y.maxBackups = x.x
+}
+
+// RxJSONLSink 将消息以 JSON Lines 格式追加写入文件的 RxSink
+//
+// 每条消息占一行，格式见 RxMessage.MarshalJSON。文件超过设定大小后，会被重命名为
+// 带有时间戳后缀的旧文件（如 rx.jsonl.20200312T144705.000），然后重新创建；
+// 重命名失败时跳过本次轮转，继续写入原文件。本类型可被并发使用。
+type RxJSONLSink struct {
+	path string
+	opts rxJSONLSinkOptions
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	closed bool
+}
+
+var _ RxSink = (*RxJSONLSink)(nil)
+
+// NewRxJSONLSink 构造一个写入 path 的 RxJSONLSink，文件已存在时追加写入
+func NewRxJSONLSink(path string, opts ...RxJSONLSinkOption) (*RxJSONLSink, error) {
+	options := rxJSONLSinkOptions{
+		maxBytes: 100 << 20,
+	}
+	for _, o := range opts {
+		o.applyTo(&options)
+	}
+
+	s := &RxJSONLSink{
+		path: path,
+		opts: options,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RxJSONLSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	s.f = f
+	s.size = fi.Size()
+	return nil
+}
+
+// Consume 写入一条消息
+func (s *RxJSONLSink) Consume(_ context.Context, msg *RxMessage) error {
+	line, err := marshalRxMessageLine(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return os.ErrClosed
+	}
+	// a previous rotation failed to reopen the file
+	if s.f == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	if s.opts.maxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.opts.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.f.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// marshalRxMessageLine 将消息序列化为一行 JSON，不转义 HTML 字符以便阅读
+func marshalRxMessageLine(msg *RxMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// MarshalJSON 的输出已被 json.Marshal 转义，此处直接编码底层结构
+	err := enc.Encode(msg.intoJSONObject())
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *RxJSONLSink) rotate() error {
+	err := s.f.Close()
+	// the file is unusable either way; the next Consume reopens it
+	s.f = nil
+	if err != nil {
+		return err
+	}
+
+	backup := s.path + "." + time.Now().Format(rxJSONLBackupTimeLayout)
+	// don't clobber a backup rotated within the same millisecond
+	for i := 1; ; i++ {
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s.%s-%d", s.path, time.Now().Format(rxJSONLBackupTimeLayout), i)
+	}
+	if err := os.Rename(s.path, backup); err != nil {
+		// skip this rotation and keep appending to the original file
+		if openErr := s.open(); openErr != nil {
+			return openErr
+		}
+		logrus.Warnf("rx jsonl sink: failed to rotate %s: %+v", s.path, err)
+		return nil
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	return s.removeOldBackups()
+}
+
+// rxJSONLBackupTimeLayout 轮转出的备份文件名后缀的时间格式
+const rxJSONLBackupTimeLayout = "20060102T150405.000"
+
+// isRxJSONLBackupSuffix 判断文件名后缀是否为 rotate 生成的，即时间戳加上可选的 "-N"
+func isRxJSONLBackupSuffix(suffix string) bool {
+	ts := suffix
+	if i := strings.IndexByte(suffix, '-'); i >= 0 {
+		ts = suffix[:i]
+		if _, err := strconv.ParseUint(suffix[i+1:], 10, 64); err != nil {
+			return false
+		}
+	}
+
+	_, err := time.Parse(rxJSONLBackupTimeLayout, ts)
+	return err == nil
+}
+
+func (s *RxJSONLSink) removeOldBackups() error {
+	if s.opts.maxBackups <= 0 {
+		return nil
+	}
+
+	dir, base := filepath.Split(s.path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// only touch files named by rotate, leaving alone anything else that
+	// happens to share the prefix
+	var l []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		if !isRxJSONLBackupSuffix(name[len(base)+1:]) {
+			continue
+		}
+		l = append(l, filepath.Join(dir, name))
+	}
+	// timestamp suffixes sort chronologically
+	sort.Strings(l)
+
+	for len(l) > s.opts.maxBackups {
+		if err := os.Remove(l[0]); err != nil {
+			return err
+		}
+		l = l[1:]
+	}
+	return nil
+}
+
+// Close 关闭文件，之后的写入都会失败
+func (s *RxJSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+//
+//
+//
+
+const (
+	// RxRelayTimestampHeader HTTP 转发请求中携带时间戳（Unix 秒）的请求头
+	RxRelayTimestampHeader = "X-Workwx-Relay-Timestamp"
+	// RxRelaySignatureHeader HTTP 转发请求中携带签名的请求头
+	RxRelaySignatureHeader = "X-Workwx-Relay-Signature"
+)
+
+type rxHTTPRelaySinkOptions struct {
+	client *http.Client
+	header http.Header
+}
+
+// RxHTTPRelaySinkOption HTTP 转发 RxSink 构造参数
+type RxHTTPRelaySinkOption interface {
+	applyTo(*rxHTTPRelaySinkOptions)
+}
+
+type withRxHTTPRelayClient struct {
+	x *http.Client
+}
+
+// WithRxHTTPRelayClient 使用给定的 http.Client 发出转发请求
+//
+// 默认使用超时为 10 秒的 http.Client。
+func WithRxHTTPRelayClient(client *http.Client) RxHTTPRelaySinkOption {
+	return &withRxHTTPRelayClient{x: client}
+}
+
+func (x *withRxHTTPRelayClient) applyTo(y *rxHTTPRelaySinkOptions) {
+	y.client = x.x
+}
+
+type withRxHTTPRelayHeader struct {
+	key   string
+	value string
+}
+
+// WithRxHTTPRelayHeader 在转发请求中附加请求头，可多次使用
+func WithRxHTTPRelayHeader(key, value string) RxHTTPRelaySinkOption {
+	return &withRxHTTPRelayHeader{key: key, value: value}
+}
+
+func (x *withRxHTTPRelayHeader) applyTo(y *rxHTTPRelaySinkOptions) {
+	y.header.Add(x.key, x.value)
+}
+
+type rxHTTPRelaySink struct {
+	url    string
+	secret []byte
+	opts   rxHTTPRelaySinkOptions
+}
+
+var _ RxSink = (*rxHTTPRelaySink)(nil)
+
+// NewRxHTTPRelaySink 构造一个将消息以 JSON 格式 POST 到 url 的 RxSink
+//
+// 请求体格式见 RxMessage.MarshalJSON。请求头 RxRelayTimestampHeader 为发出请求时的
+// Unix 时间戳，RxRelaySignatureHeader 为以 secret 为密钥、对“时间戳 + "." + 请求体”
+// 计算的 HMAC-SHA256 的十六进制表示，接收方可用 VerifyRxRelaySignature 校验。
+// 对方响应非 2xx 状态码时视为投递失败。
+func NewRxHTTPRelaySink(url string, secret string, opts ...RxHTTPRelaySinkOption) RxSink {
+	options := rxHTTPRelaySinkOptions{
+		client: &http.Client{Timeout: 10 * time.Second},
+		header: make(http.Header),
+	}
+	for _, o := range opts {
+		o.applyTo(&options)
+	}
+
+	return &rxHTTPRelaySink{
+		url:    url,
+		secret: []byte(secret),
+		opts:   options,
+	}
+}
+
+func (s *rxHTTPRelaySink) Consume(ctx context.Context, msg *RxMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	for k, v := range s.opts.header {
+		req.Header[k] = v
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RxRelayTimestampHeader, ts)
+	req.Header.Set(RxRelaySignatureHeader, rxRelaySignature(s.secret, ts, body))
+
+	resp, err := s.opts.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("rx relay to %s failed: %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func rxRelaySignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte{'.'})
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRxRelaySignature 校验 NewRxHTTPRelaySink 发出的转发请求的签名
+//
+// timestamp 与 signature 分别为请求头 RxRelayTimestampHeader 与
+// RxRelaySignatureHeader 的值，body 为请求体。如需防重放，还应自行检查时间戳。
+func VerifyRxRelaySignature(secret string, timestamp string, body []byte, signature string) bool {
+	expected := rxRelaySignature([]byte(secret), timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
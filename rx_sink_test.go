@@ -0,0 +1,224 @@
+package workwx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRxMessageMarshalJSON(t *testing.T) {
+	c.Convey("将接收的消息序列化为 JSON", t, func() {
+		c.Convey("文本消息", func() {
+			body := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			b, err := json.Marshal(msg)
+			c.So(err, c.ShouldBeNil)
+
+			var obj map[string]interface{}
+			c.So(json.Unmarshal(b, &obj), c.ShouldBeNil)
+			c.So(obj, c.ShouldResemble, map[string]interface{}{
+				"corp_id":      "ww6a112864f8022910",
+				"from_user_id": "foobar",
+				"send_time":    float64(1583995625),
+				"msg_type":     "text",
+				"msg_id":       float64(2018405441),
+				"agent_id":     float64(1000002),
+				"extras":       map[string]interface{}{"Content": "x123"},
+				"raw_xml":      string(body),
+			})
+		})
+
+		c.Convey("未知类型的消息只带有原始 XML", func() {
+			body := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[some_future_event]]></Event></xml>")
+			msg, err := fromEnvelope(body)
+			c.So(err, c.ShouldBeNil)
+
+			b, err := json.Marshal(msg)
+			c.So(err, c.ShouldBeNil)
+
+			var obj map[string]interface{}
+			c.So(json.Unmarshal(b, &obj), c.ShouldBeNil)
+			c.So(obj["event"], c.ShouldEqual, "some_future_event")
+			c.So(obj, c.ShouldNotContainKey, "extras")
+			c.So(obj["raw_xml"], c.ShouldEqual, string(body))
+		})
+	})
+}
+
+func TestRxSinks(t *testing.T) {
+	body := []byte("<xml><ToUserName><![CDATA[ww6a112864f8022910]]></ToUserName><FromUserName><![CDATA[foobar]]></FromUserName><CreateTime>1583995625</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[x123]]></Content><MsgId>2018405441</MsgId><AgentID>1000002</AgentID></xml>")
+	msg, err := fromEnvelope(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	c.Convey("备份文件名后缀", t, func() {
+		c.So(isRxJSONLBackupSuffix("20200312T143000.123"), c.ShouldBeTrue)
+		c.So(isRxJSONLBackupSuffix("20200312T143000.123-2"), c.ShouldBeTrue)
+		c.So(isRxJSONLBackupSuffix("keep"), c.ShouldBeFalse)
+		c.So(isRxJSONLBackupSuffix("20200312T143000.123.gz"), c.ShouldBeFalse)
+		c.So(isRxJSONLBackupSuffix("20200312T143000.123-x"), c.ShouldBeFalse)
+	})
+
+	c.Convey("回调消息投递", t, func() {
+		c.Convey("channel", func() {
+			ch := make(chan *RxMessage, 1)
+			s := NewRxChanSink(ch)
+			c.So(s.Consume(ctx, msg), c.ShouldBeNil)
+			c.So(<-ch, c.ShouldEqual, msg)
+
+			// full channel and cancelled context
+			ch <- msg
+			cctx, cancel := context.WithCancel(ctx)
+			cancel()
+			c.So(s.Consume(cctx, msg), c.ShouldEqual, context.Canceled)
+		})
+
+		c.Convey("JSONL 文件与轮转", func() {
+			dir, err := ioutil.TempDir("", "workwx-rx-sink")
+			c.So(err, c.ShouldBeNil)
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "rx.jsonl")
+			// unrelated files sharing the prefix must survive rotation
+			for _, name := range []string{path + ".keep", path + ".lock", path + ".20200101T000000.000.gz"} {
+				c.So(ioutil.WriteFile(name, []byte("x"), 0644), c.ShouldBeNil)
+			}
+			line, err := marshalRxMessageLine(msg)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(line), c.ShouldContainSubstring, `"raw_xml":"<xml>`)
+
+			// room for exactly two lines per file
+			s, err := NewRxJSONLSink(
+				path,
+				WithRxJSONLMaxBytes(int64(2*len(line))),
+				WithRxJSONLMaxBackups(1),
+			)
+			c.So(err, c.ShouldBeNil)
+
+			for i := 0; i < 3; i++ {
+				c.So(s.Consume(ctx, msg), c.ShouldBeNil)
+			}
+
+			content, err := ioutil.ReadFile(path)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(content), c.ShouldEqual, string(line))
+
+			backups, err := filepath.Glob(path + ".2*")
+			c.So(err, c.ShouldBeNil)
+			c.So(backups, c.ShouldHaveLength, 2)
+			c.So(backups[0], c.ShouldEqual, path+".20200101T000000.000.gz")
+			backups = backups[1:]
+			for _, name := range []string{path + ".keep", path + ".lock"} {
+				_, err := os.Stat(name)
+				c.So(err, c.ShouldBeNil)
+			}
+			content, err = ioutil.ReadFile(backups[0])
+			c.So(err, c.ShouldBeNil)
+			c.So(strings.Count(string(content), "\n"), c.ShouldEqual, 2)
+
+			c.So(s.Close(), c.ShouldBeNil)
+			c.So(s.Consume(ctx, msg), c.ShouldEqual, os.ErrClosed)
+		})
+
+		c.Convey("JSONL 文件轮转失败时继续写入", func() {
+			dir, err := ioutil.TempDir("", "workwx-rx-sink")
+			c.So(err, c.ShouldBeNil)
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "rx.jsonl")
+			line, err := marshalRxMessageLine(msg)
+			c.So(err, c.ShouldBeNil)
+
+			s, err := NewRxJSONLSink(path, WithRxJSONLMaxBytes(int64(len(line))))
+			c.So(err, c.ShouldBeNil)
+			defer s.Close()
+			c.So(s.Consume(ctx, msg), c.ShouldBeNil)
+
+			// removing the file out from under the sink makes the rename fail
+			c.So(os.Remove(path), c.ShouldBeNil)
+			c.So(s.Consume(ctx, msg), c.ShouldBeNil)
+			c.So(s.Consume(ctx, msg), c.ShouldBeNil)
+
+			content, err := ioutil.ReadFile(path)
+			c.So(err, c.ShouldBeNil)
+			c.So(string(content), c.ShouldEqual, string(line))
+			backups, err := filepath.Glob(path + ".2*")
+			c.So(err, c.ShouldBeNil)
+			c.So(backups, c.ShouldHaveLength, 1)
+		})
+
+		c.Convey("HTTP 转发", func() {
+			status := http.StatusOK
+			var verified bool
+			var lastBody []byte
+			var lastHeader http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				lastBody, _ = ioutil.ReadAll(r.Body)
+				lastHeader = r.Header
+				verified = VerifyRxRelaySignature(
+					"s3cret",
+					r.Header.Get(RxRelayTimestampHeader),
+					lastBody,
+					r.Header.Get(RxRelaySignatureHeader),
+				)
+				rw.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			s := NewRxHTTPRelaySink(server.URL, "s3cret", WithRxHTTPRelayHeader("X-Env", "test"))
+
+			c.So(s.Consume(ctx, msg), c.ShouldBeNil)
+			c.So(verified, c.ShouldBeTrue)
+			c.So(lastHeader.Get("Content-Type"), c.ShouldEqual, "application/json")
+			c.So(lastHeader.Get("X-Env"), c.ShouldEqual, "test")
+			line, _ := json.Marshal(msg)
+			c.So(string(lastBody), c.ShouldEqual, string(line))
+
+			c.So(VerifyRxRelaySignature("wrong", lastHeader.Get(RxRelayTimestampHeader), lastBody, lastHeader.Get(RxRelaySignatureHeader)), c.ShouldBeFalse)
+
+			status = http.StatusBadGateway
+			c.So(s.Consume(ctx, msg), c.ShouldNotBeNil)
+		})
+
+		c.Convey("fan-out", func() {
+			var got []string
+			ok := func(name string) RxSink {
+				return RxSinkFunc(func(_ context.Context, _ *RxMessage) error {
+					got = append(got, name)
+					return nil
+				})
+			}
+			boom := errors.New("boom")
+			bad := RxSinkFunc(func(_ context.Context, _ *RxMessage) error {
+				got = append(got, "bad")
+				return boom
+			})
+
+			reply, err := NewRxFanoutHandler(ok("a"), ok("b")).OnIncomingMessage(ctx, msg)
+			c.So(reply, c.ShouldBeNil)
+			c.So(err, c.ShouldBeNil)
+			c.So(got, c.ShouldResemble, []string{"a", "b"})
+
+			got = nil
+			_, err = NewRxFanoutHandler(ok("a"), bad, ok("b")).OnIncomingMessage(ctx, msg)
+			c.So(got, c.ShouldResemble, []string{"a", "bad", "b"})
+			var fe *RxFanoutError
+			c.So(errors.As(err, &fe), c.ShouldBeTrue)
+			c.So(fe.Errors, c.ShouldResemble, []error{nil, boom, nil})
+			c.So(err.Error(), c.ShouldEqual, "rx fanout failed: sink #1: boom")
+		})
+	})
+}
@@ -0,0 +1,165 @@
+// Code generated by sdkcodegen; DO NOT EDIT.
+
+package workwx
+
+// TemplateCard 模板卡片消息
+type TemplateCard struct {
+	// CardType 模板卡片的模板类型，文本通知模版卡片的类型为text_notice，图文展示模版卡片的类型为news_notice
+	CardType TemplateCardType `json:"card_type"`
+	// Source 卡片来源样式信息，不需要来源样式可不填写
+	Source *TemplateCardSource `json:"source,omitempty"`
+	// MainTitle 模版卡片的主要内容，包括一级标题和标题辅助信息
+	MainTitle *TemplateCardMainTitle `json:"main_title,omitempty"`
+	// EmphasisContent 关键数据样式，建议不与引用样式共用
+	EmphasisContent *TemplateCardEmphasisContent `json:"emphasis_content,omitempty"`
+	// QuoteArea 引用文献样式，建议不与关键数据共用
+	QuoteArea *TemplateCardQuoteArea `json:"quote_area,omitempty"`
+	// SubTitleText 二级普通文本，建议不超过112个字。模版卡片主要内容的一级标题main_title.title和二级普通文本sub_title_text必须有一项填写
+	SubTitleText string `json:"sub_title_text,omitempty"`
+	// HorizontalContentList 二级标题+文本列表，该字段可为空数组，但有数据的话需确认对应字段是否必填，列表长度不超过6
+	HorizontalContentList []TemplateCardHorizontalContent `json:"horizontal_content_list,omitempty"`
+	// JumpList 跳转指引样式的列表，该字段可为空数组，但有数据的话需确认对应字段是否必填，列表长度不超过3
+	JumpList []TemplateCardJump `json:"jump_list,omitempty"`
+	// CardAction 整体卡片的点击跳转事件，text_notice模版卡片中该字段为必填项
+	CardAction TemplateCardAction `json:"card_action"`
+	// CardImage 图片样式，news_notice类型的卡片，card_image和image_text_area两者必填一个字段，不可都不填
+	CardImage *TemplateCardImage `json:"card_image,omitempty"`
+	// ImageTextArea 左图右文样式
+	ImageTextArea *TemplateCardImageTextArea `json:"image_text_area,omitempty"`
+	// VerticalContentList 卡片二级垂直内容，该字段可为空数组，但有数据的话需确认对应字段是否必填，列表长度不超过4
+	VerticalContentList []TemplateCardVerticalContent `json:"vertical_content_list,omitempty"`
+}
+
+// TemplateCardType 模板卡片的模板类型
+type TemplateCardType string
+
+const (
+	// TemplateCardTypeTextNotice 文本通知模版卡片
+	TemplateCardTypeTextNotice TemplateCardType = "text_notice"
+	// TemplateCardTypeNewsNotice 图文展示模版卡片
+	TemplateCardTypeNewsNotice TemplateCardType = "news_notice"
+	// TemplateCardTypeButtonInteraction 按钮交互型模版卡片，仅见于模板卡片事件
+	TemplateCardTypeButtonInteraction TemplateCardType = "button_interaction"
+	// TemplateCardTypeVoteInteraction 投票选择型模版卡片，仅见于模板卡片事件
+	TemplateCardTypeVoteInteraction TemplateCardType = "vote_interaction"
+	// TemplateCardTypeMultipleInteraction 多项选择型模版卡片，仅见于模板卡片事件
+	TemplateCardTypeMultipleInteraction TemplateCardType = "multiple_interaction"
+)
+
+// TemplateCardSource 卡片来源样式信息
+type TemplateCardSource struct {
+	// IconURL 来源图片的url
+	IconURL string `json:"icon_url,omitempty"`
+	// Desc 来源图片的描述，建议不超过13个字
+	Desc string `json:"desc,omitempty"`
+	// DescColor 来源文字的颜色，目前支持：0(默认) 灰色，1 黑色，2 红色，3 绿色
+	DescColor int `json:"desc_color,omitempty"`
+}
+
+// TemplateCardMainTitle 模版卡片的主要内容
+type TemplateCardMainTitle struct {
+	// Title 一级标题，建议不超过26个字
+	Title string `json:"title,omitempty"`
+	// Desc 标题辅助信息，建议不超过30个字
+	Desc string `json:"desc,omitempty"`
+}
+
+// TemplateCardEmphasisContent 关键数据样式
+type TemplateCardEmphasisContent struct {
+	// Title 关键数据样式的数据内容，建议不超过10个字
+	Title string `json:"title,omitempty"`
+	// Desc 关键数据样式的数据描述内容，建议不超过15个字
+	Desc string `json:"desc,omitempty"`
+}
+
+// TemplateCardQuoteArea 引用文献样式
+type TemplateCardQuoteArea struct {
+	// Type 引用文献样式区域点击事件，0或不填代表没有点击事件，1 代表跳转url，2 代表跳转小程序
+	Type int `json:"type,omitempty"`
+	// URL 点击跳转的url，quote_area.type是1时必填
+	URL string `json:"url,omitempty"`
+	// AppID 点击跳转的小程序的appid，quote_area.type是2时必填
+	AppID string `json:"appid,omitempty"`
+	// PagePath 点击跳转的小程序的pagepath，quote_area.type是2时选填
+	PagePath string `json:"pagepath,omitempty"`
+	// Title 引用文献样式的标题
+	Title string `json:"title,omitempty"`
+	// QuoteText 引用文献样式的引用文案
+	QuoteText string `json:"quote_text,omitempty"`
+}
+
+// TemplateCardHorizontalContent 二级标题+文本
+type TemplateCardHorizontalContent struct {
+	// Type 链接类型，0或不填代表是普通文本，1 代表跳转url，2 代表下载附件，3 代表@员工
+	Type int `json:"type,omitempty"`
+	// KeyName 二级标题，建议不超过5个字
+	KeyName string `json:"keyname"`
+	// Value 二级文本，如果horizontal_content_list.type是2，该字段代表文件名称（要包含文件类型），建议不超过26个字
+	Value string `json:"value,omitempty"`
+	// URL 链接跳转的url，horizontal_content_list.type是1时必填
+	URL string `json:"url,omitempty"`
+	// MediaID 附件的media_id，horizontal_content_list.type是2时必填
+	MediaID string `json:"media_id,omitempty"`
+	// UserID 被@的成员的userid，horizontal_content_list.type是3时必填
+	UserID string `json:"userid,omitempty"`
+}
+
+// TemplateCardJump 跳转指引样式
+type TemplateCardJump struct {
+	// Type 跳转链接类型，0或不填代表不是链接，1 代表跳转url，2 代表跳转小程序
+	Type int `json:"type,omitempty"`
+	// Title 跳转链接样式的文案内容，建议不超过13个字
+	Title string `json:"title"`
+	// URL 跳转链接的url，jump_list.type是1时必填
+	URL string `json:"url,omitempty"`
+	// AppID 跳转链接的小程序的appid，jump_list.type是2时必填
+	AppID string `json:"appid,omitempty"`
+	// PagePath 跳转链接的小程序的pagepath，jump_list.type是2时选填
+	PagePath string `json:"pagepath,omitempty"`
+}
+
+// TemplateCardAction 整体卡片的点击跳转事件
+type TemplateCardAction struct {
+	// Type 卡片跳转类型，1 代表跳转url，2 代表打开小程序。text_notice模版卡片中该字段取值范围为[1,2]
+	Type int `json:"type"`
+	// URL 跳转事件的url，card_action.type是1时必填
+	URL string `json:"url,omitempty"`
+	// AppID 跳转事件的小程序的appid，card_action.type是2时必填
+	AppID string `json:"appid,omitempty"`
+	// PagePath 跳转事件的小程序的pagepath，card_action.type是2时选填
+	PagePath string `json:"pagepath,omitempty"`
+}
+
+// TemplateCardImage 图片样式
+type TemplateCardImage struct {
+	// URL 图片的url
+	URL string `json:"url"`
+	// AspectRatio 图片的宽高比，宽高比要小于2.25，大于1.3，不填该参数默认1.3
+	AspectRatio float64 `json:"aspect_ratio,omitempty"`
+}
+
+// TemplateCardImageTextArea 左图右文样式
+type TemplateCardImageTextArea struct {
+	// Type 左图右文样式区域点击事件，0或不填代表没有点击事件，1 代表跳转url，2 代表跳转小程序
+	Type int `json:"type,omitempty"`
+	// URL 点击跳转的url，image_text_area.type是1时必填
+	URL string `json:"url,omitempty"`
+	// AppID 点击跳转的小程序的appid，image_text_area.type是2时必填
+	AppID string `json:"appid,omitempty"`
+	// PagePath 点击跳转的小程序的pagepath，image_text_area.type是2时选填
+	PagePath string `json:"pagepath,omitempty"`
+	// Title 左图右文样式的标题
+	Title string `json:"title,omitempty"`
+	// Desc 左图右文样式的描述
+	Desc string `json:"desc,omitempty"`
+	// ImageURL 左图右文样式的图片url
+	ImageURL string `json:"image_url"`
+}
+
+// TemplateCardVerticalContent 卡片二级垂直内容
+type TemplateCardVerticalContent struct {
+	// Title 卡片二级标题，建议不超过26个字
+	Title string `json:"title"`
+	// Desc 二级普通文本，建议不超过112个字
+	Desc string `json:"desc,omitempty"`
+}
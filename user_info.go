@@ -1,5 +1,13 @@
 package workwx
 
+// CreateUser 创建成员
+func (c *WorkwxApp) CreateUser(user *UserCreateRequest) error {
+	_, err := c.execUserCreate(reqUserCreate{
+		User: user,
+	})
+	return err
+}
+
 // GetUser 读取成员
 func (c *WorkwxApp) GetUser(userid string) (*UserInfo, error) {
 	resp, err := c.execUserGet(reqUserGet{
@@ -14,6 +22,32 @@ func (c *WorkwxApp) GetUser(userid string) (*UserInfo, error) {
 	return &obj, nil
 }
 
+// UpdateUser 更新成员
+//
+// 只会修改 update 中非 nil 的字段；UserID 必填。
+func (c *WorkwxApp) UpdateUser(update *UserUpdateRequest) error {
+	_, err := c.execUserUpdate(reqUserUpdate{
+		User: update,
+	})
+	return err
+}
+
+// DeleteUser 删除成员
+func (c *WorkwxApp) DeleteUser(userid string) error {
+	_, err := c.execUserDelete(reqUserDelete{
+		UserID: userid,
+	})
+	return err
+}
+
+// BatchDeleteUsers 批量删除成员，每次最多 200 个
+func (c *WorkwxApp) BatchDeleteUsers(userids []string) error {
+	_, err := c.execUserBatchDelete(reqUserBatchDelete{
+		UserIDs: userids,
+	})
+	return err
+}
+
 // ListUsersByDeptID 获取部门成员详情
 func (c *WorkwxApp) ListUsersByDeptID(deptID int64, fetchChild bool) ([]*UserInfo, error) {
 	resp, err := c.execUserList(reqUserList{
@@ -75,6 +75,96 @@ type UserDeptInfo struct {
 	IsLeader bool
 }
 
+// UserCreateRequest 创建成员参数
+type UserCreateRequest struct {
+	// UserID 成员UserID。对应管理端的账号，企业内必须唯一。长度为1~64个字节
+	UserID string
+	// Name 成员名称。长度为1~64个utf8字符
+	Name string
+	// Alias 成员别名。长度1~64个utf8字符
+	Alias string
+	// Mobile 手机号码。企业内必须唯一，Mobile/Email二者不能同时为空
+	Mobile string
+	// Departments 成员所属部门信息，不超过100个。为空时由企业微信分配到根部门
+	Departments []UserDeptInfo
+	// MainDepartment 主部门，为零值时不指定
+	MainDepartment int64
+	// DirectLeaders 直属上级UserID，最多5个
+	DirectLeaders []string
+	// Position 职务信息
+	Position string
+	// Gender 性别，为零值时不指定
+	Gender UserGender
+	// Email 邮箱。企业内必须唯一，Mobile/Email二者不能同时为空
+	Email string
+	// Telephone 座机
+	Telephone string
+	// Address 地址
+	Address string
+	// IsEnabled 启用/禁用成员，为 nil 时默认启用
+	IsEnabled *bool
+	// AvatarMediaID 成员头像的mediaid，通过素材管理接口上传图片获得
+	AvatarMediaID string
+	// ExtAttrs 自定义字段。自定义字段需要先在WEB管理端添加，见扩展属性添加方法，否则忽略未知属性的赋值
+	ExtAttrs []UserExtAttr
+	// ToInvite 是否邀请该成员使用企业微信（将通过微信服务通知或短信或邮件下发邀请，每天自动下发一次，最多持续3个工作日），为 nil 时默认邀请
+	ToInvite *bool
+	// ExternalProfile 成员对外属性
+	ExternalProfile *ExternalProfile
+	// ExternalPosition 对外职务，如果设置了该值，则以此作为对外展示的职务，否则以Position来展示。长度12个汉字内
+	ExternalPosition string
+}
+
+// UserUpdateRequest 更新成员参数
+type UserUpdateRequest struct {
+	// UserID 成员UserID，必填
+	UserID string
+	// Name 成员名称
+	Name *string
+	// Alias 成员别名
+	Alias *string
+	// Mobile 手机号码
+	Mobile *string
+	// Departments 成员所属部门信息，为 nil 时不修改
+	Departments []UserDeptInfo
+	// MainDepartment 主部门
+	MainDepartment *int64
+	// DirectLeaders 直属上级UserID，为 nil 时不修改，为空切片时清空
+	DirectLeaders []string
+	// Position 职务信息
+	Position *string
+	// Gender 性别
+	Gender *UserGender
+	// Email 邮箱
+	Email *string
+	// Telephone 座机
+	Telephone *string
+	// Address 地址
+	Address *string
+	// IsEnabled 启用/禁用成员
+	IsEnabled *bool
+	// AvatarMediaID 成员头像的mediaid
+	AvatarMediaID *string
+	// ExtAttrs 自定义字段，为 nil 时不修改，为空切片时清空
+	ExtAttrs []UserExtAttr
+	// ExternalProfile 成员对外属性
+	ExternalProfile *ExternalProfile
+	// ExternalPosition 对外职务
+	ExternalPosition *string
+}
+
+// UserExtAttr 成员扩展属性
+type UserExtAttr struct {
+	// Type 属性类型: 0-文本 1-网页
+	Type int `json:"type"`
+	// Name 属性名称，需要先确保在管理端有创建该属性，否则会忽略
+	Name string `json:"name"`
+	// Text 文本类型的属性，Type为0时必填
+	Text *ExternalAttrText `json:"text,omitempty"`
+	// Web 网页类型的属性，Type为1时必填
+	Web *ExternalAttrWeb `json:"web,omitempty"`
+}
+
 // UserIdentityInfo 访问用户身份信息
 type UserIdentityInfo struct {
 	// UserID 成员UserID。若需要获得用户详情信息，可调用通讯录接口：读取成员。如果是互联企业，则返回的UserId格式如：CorpId/userid
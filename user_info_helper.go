@@ -55,3 +55,135 @@ func (x respUserDetail) intoUserInfo() UserInfo {
 		QRCodeURL:   x.QRCodeURL,
 	}
 }
+
+func flattenDeptInfo(depts []UserDeptInfo) (ids []int64, orders []uint32, leaderStatuses []int) {
+	if depts == nil {
+		return nil, nil, nil
+	}
+
+	ids = make([]int64, len(depts))
+	orders = make([]uint32, len(depts))
+	leaderStatuses = make([]int, len(depts))
+	for i, d := range depts {
+		ids[i] = d.DeptID
+		orders[i] = d.Order
+		if d.IsLeader {
+			leaderStatuses[i] = 1
+		}
+	}
+
+	return ids, orders, leaderStatuses
+}
+
+func optionalString(x string) *string {
+	if x == "" {
+		return nil
+	}
+	return &x
+}
+
+func optionalGenderStr(x *UserGender) *string {
+	if x == nil {
+		return nil
+	}
+	s := strconv.Itoa(int(*x))
+	return &s
+}
+
+func optionalEnableInt(x *bool) *int {
+	if x == nil {
+		return nil
+	}
+	var n int
+	if *x {
+		n = 1
+	}
+	return &n
+}
+
+func optionalStrings(x []string) *[]string {
+	if x == nil {
+		return nil
+	}
+	return &x
+}
+
+func optionalExtAttrs(x []UserExtAttr) *userExtAttrs {
+	if x == nil {
+		return nil
+	}
+	return &userExtAttrs{Attrs: x}
+}
+
+func optionalExternalProfile(x *ExternalProfile) *userExternalProfile {
+	if x == nil {
+		return nil
+	}
+	obj := userExternalProfile{ExternalCorpName: x.ExternalCorpName}
+	if x.ExternalAttr != nil {
+		obj.ExternalAttr = &x.ExternalAttr
+	}
+	return &obj
+}
+
+func (x *UserCreateRequest) intoUserMutation() userMutation {
+	deptIDs, deptOrder, isLeaderInDept := flattenDeptInfo(x.Departments)
+
+	var mainDept *int64
+	if x.MainDepartment != 0 {
+		mainDept = &x.MainDepartment
+	}
+	var gender *UserGender
+	if x.Gender != UserGenderUnspecified {
+		gender = &x.Gender
+	}
+
+	return userMutation{
+		UserID:           x.UserID,
+		Name:             &x.Name,
+		Alias:            optionalString(x.Alias),
+		Mobile:           optionalString(x.Mobile),
+		DeptIDs:          deptIDs,
+		DeptOrder:        deptOrder,
+		IsLeaderInDept:   isLeaderInDept,
+		DirectLeader:     optionalStrings(x.DirectLeaders),
+		MainDepartment:   mainDept,
+		Position:         optionalString(x.Position),
+		Gender:           optionalGenderStr(gender),
+		Email:            optionalString(x.Email),
+		Telephone:        optionalString(x.Telephone),
+		Address:          optionalString(x.Address),
+		Enable:           optionalEnableInt(x.IsEnabled),
+		AvatarMediaID:    optionalString(x.AvatarMediaID),
+		ExtAttr:          optionalExtAttrs(x.ExtAttrs),
+		ToInvite:         x.ToInvite,
+		ExternalProfile:  optionalExternalProfile(x.ExternalProfile),
+		ExternalPosition: optionalString(x.ExternalPosition),
+	}
+}
+
+func (x *UserUpdateRequest) intoUserMutation() userMutation {
+	deptIDs, deptOrder, isLeaderInDept := flattenDeptInfo(x.Departments)
+
+	return userMutation{
+		UserID:           x.UserID,
+		Name:             x.Name,
+		Alias:            x.Alias,
+		Mobile:           x.Mobile,
+		DeptIDs:          deptIDs,
+		DeptOrder:        deptOrder,
+		IsLeaderInDept:   isLeaderInDept,
+		DirectLeader:     optionalStrings(x.DirectLeaders),
+		MainDepartment:   x.MainDepartment,
+		Position:         x.Position,
+		Gender:           optionalGenderStr(x.Gender),
+		Email:            x.Email,
+		Telephone:        x.Telephone,
+		Address:          x.Address,
+		Enable:           optionalEnableInt(x.IsEnabled),
+		AvatarMediaID:    x.AvatarMediaID,
+		ExtAttr:          optionalExtAttrs(x.ExtAttrs),
+		ExternalProfile:  optionalExternalProfile(x.ExternalProfile),
+		ExternalPosition: x.ExternalPosition,
+	}
+}
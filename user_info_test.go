@@ -0,0 +1,161 @@
+package workwx
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUserMutations(t *testing.T) {
+	c.Convey("成员管理", t, func() {
+		var lastPath string
+		var lastQuery string
+		var lastBody map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/cgi-bin/gettoken" {
+				_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+				return
+			}
+
+			lastPath = r.URL.Path
+			lastQuery = r.URL.Query().Get("userid")
+			lastBody = nil
+			body, _ := ioutil.ReadAll(r.Body)
+			if len(body) > 0 {
+				_ = json.Unmarshal(body, &lastBody)
+			}
+
+			if lastQuery == "nobody" {
+				_, _ = rw.Write([]byte(`{"errcode":60111,"errmsg":"invalid string value"}`))
+				return
+			}
+			_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}))
+		defer server.Close()
+
+		app := New("ww6a112864f8022910", WithQYAPIHost(server.URL)).WithApp("secret", 1000001)
+
+		c.Convey("创建成员", func() {
+			toInvite := false
+			err := app.CreateUser(&UserCreateRequest{
+				UserID: "zhangsan",
+				Name:   "张三",
+				Mobile: "13800000000",
+				Departments: []UserDeptInfo{
+					{DeptID: 1, Order: 10, IsLeader: true},
+					{DeptID: 2},
+				},
+				MainDepartment: 2,
+				Gender:         UserGenderMale,
+				AvatarMediaID:  "2-G6nrLmr5EC3MNb_-zL1dDdzkd0p7cNliYu9V5w7o8K0",
+				ExtAttrs: []UserExtAttr{
+					{Type: 0, Name: "爱好", Text: &ExternalAttrText{Value: "旅游"}},
+				},
+				ToInvite: &toInvite,
+				ExternalProfile: &ExternalProfile{
+					ExternalCorpName: "企业简称",
+				},
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/user/create")
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"userid":            "zhangsan",
+				"name":              "张三",
+				"mobile":            "13800000000",
+				"department":        []interface{}{float64(1), float64(2)},
+				"order":             []interface{}{float64(10), float64(0)},
+				"is_leader_in_dept": []interface{}{float64(1), float64(0)},
+				"main_department":   float64(2),
+				"gender":            "1",
+				"avatar_mediaid":    "2-G6nrLmr5EC3MNb_-zL1dDdzkd0p7cNliYu9V5w7o8K0",
+				"extattr": map[string]interface{}{
+					"attrs": []interface{}{
+						map[string]interface{}{
+							"type": float64(0),
+							"name": "爱好",
+							"text": map[string]interface{}{"value": "旅游"},
+						},
+					},
+				},
+				"to_invite": false,
+				"external_profile": map[string]interface{}{
+					"external_corp_name": "企业简称",
+				},
+			})
+		})
+
+		c.Convey("部分更新成员", func() {
+			position := ""
+			enabled := false
+			err := app.UpdateUser(&UserUpdateRequest{
+				UserID:    "zhangsan",
+				Position:  &position,
+				IsEnabled: &enabled,
+				ExtAttrs:  []UserExtAttr{},
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/user/update")
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"userid":   "zhangsan",
+				"position": "",
+				"enable":   float64(0),
+				"extattr":  map[string]interface{}{"attrs": []interface{}{}},
+			})
+		})
+
+		c.Convey("更新成员对外属性", func() {
+			err := app.UpdateUser(&UserUpdateRequest{
+				UserID: "zhangsan",
+				ExternalProfile: &ExternalProfile{
+					ExternalCorpName: "企业简称",
+					ExternalAttr:     []ExternalAttr{},
+				},
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"userid": "zhangsan",
+				"external_profile": map[string]interface{}{
+					"external_corp_name": "企业简称",
+					"external_attr":      []interface{}{},
+				},
+			})
+		})
+
+		c.Convey("清空直属上级", func() {
+			err := app.UpdateUser(&UserUpdateRequest{
+				UserID:        "zhangsan",
+				DirectLeaders: []string{},
+			})
+			c.So(err, c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/user/update")
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"userid":        "zhangsan",
+				"direct_leader": []interface{}{},
+			})
+		})
+
+		c.Convey("删除成员", func() {
+			c.So(app.DeleteUser("zhangsan"), c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/user/delete")
+			c.So(lastQuery, c.ShouldEqual, "zhangsan")
+
+			err := app.DeleteUser("nobody")
+			c.So(err, c.ShouldNotBeNil)
+			clientErr, ok := err.(*WorkwxClientError)
+			c.So(ok, c.ShouldBeTrue)
+			c.So(clientErr.Code, c.ShouldEqual, 60111)
+		})
+
+		c.Convey("批量删除成员", func() {
+			c.So(app.BatchDeleteUsers([]string{"zhangsan", "lisi"}), c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/user/batchdelete")
+			c.So(lastBody, c.ShouldResemble, map[string]interface{}{
+				"useridlist": []interface{}{"zhangsan", "lisi"},
+			})
+		})
+	})
+}
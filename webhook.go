@@ -0,0 +1,288 @@
+package workwx
+
+import (
+	"bytes"
+	"crypto/md5" //nolint: gosec  // this is part of vendor API spec
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+)
+
+// WebhookBot 群机器人客户端
+//
+// 群机器人通过 webhook 地址中的 key 鉴权，既不需要 access token，也不隶属于任何
+// 应用，因此独立于 Workwx、WorkwxApp 构造。
+type WebhookBot struct {
+	opts options
+
+	// Key webhook 地址中的 key 参数，必填
+	Key string
+}
+
+// NewWebhookBot 构造一个群机器人客户端，需要提供 webhook 地址中的 key
+//
+// 支持的构造参数与 New 相同，如 WithQYAPIHost、WithHTTPClient。
+func NewWebhookBot(key string, opts ...CtorOption) *WebhookBot {
+	optionsObj := defaultOptions()
+
+	for _, o := range opts {
+		o.applyTo(&optionsObj)
+	}
+
+	return &WebhookBot{
+		opts: optionsObj,
+
+		Key: key,
+	}
+}
+
+// webhookImageMaxBytes 群机器人图片消息的图片（base64 编码前）最大字节数
+const webhookImageMaxBytes = 2 * 1024 * 1024
+
+var errWebhookImageTooLarge = errors.New("webhook image exceeds 2MB before base64 encoding")
+
+// SendTextMessage 发送文本消息
+//
+// mentionedUserIDs 为需要 @ 的成员 userid 列表，mentionedMobiles 为需要 @ 的
+// 成员手机号列表；二者均可为空，均支持以 "@all" 提醒所有人。
+func (c *WebhookBot) SendTextMessage(
+	content string,
+	mentionedUserIDs []string,
+	mentionedMobiles []string,
+) error {
+	obj := map[string]interface{}{
+		"content": content,
+	}
+	if len(mentionedUserIDs) > 0 {
+		obj["mentioned_list"] = mentionedUserIDs
+	}
+	if len(mentionedMobiles) > 0 {
+		obj["mentioned_mobile_list"] = mentionedMobiles
+	}
+
+	return c.sendMessage("text", obj)
+}
+
+// SendMarkdownMessage 发送 Markdown 消息
+//
+// 仅支持 Markdown 的子集，详见[官方文档](https://work.weixin.qq.com/api/doc/90000/90136/91770)。
+// 内容构造与校验可参考 MarkdownBuilder、ValidateMarkdown。
+func (c *WebhookBot) SendMarkdownMessage(content string) error {
+	return c.sendMessage("markdown", map[string]interface{}{"content": content})
+}
+
+// SendImageMessage 发送图片消息
+//
+// 图片（base64 编码前）最大不能超过 2M，支持 JPG、PNG 格式；base64 编码与 md5
+// 校验值由 SDK 自动计算。
+func (c *WebhookBot) SendImageMessage(image []byte) error {
+	if len(image) > webhookImageMaxBytes {
+		return errWebhookImageTooLarge
+	}
+
+	//nolint: gosec  // this is part of vendor API spec
+	sum := md5.Sum(image)
+	return c.sendMessage(
+		"image",
+		map[string]interface{}{
+			"base64": base64.StdEncoding.EncodeToString(image),
+			"md5":    fmt.Sprintf("%x", sum),
+		},
+	)
+}
+
+// SendNewsMessage 发送图文消息
+//
+// 一个图文消息支持 1 到 8 条图文。
+func (c *WebhookBot) SendNewsMessage(articles []NewsArticle) error {
+	if err := validateNewsArticles(articles); err != nil {
+		return err
+	}
+
+	return c.sendMessage(
+		"news",
+		map[string]interface{}{
+			"articles": articles,
+		},
+	)
+}
+
+// SendFileMessage 发送文件消息
+//
+// mediaID 需通过 UploadFileMedia 上传获得。
+func (c *WebhookBot) SendFileMessage(mediaID string) error {
+	return c.sendMessage("file", map[string]interface{}{"media_id": mediaID})
+}
+
+// SendVoiceMessage 发送语音消息
+//
+// mediaID 需通过 UploadVoiceMedia 上传获得。
+func (c *WebhookBot) SendVoiceMessage(mediaID string) error {
+	return c.sendMessage("voice", map[string]interface{}{"media_id": mediaID})
+}
+
+// SendTemplateCardMessage 发送模板卡片消息
+func (c *WebhookBot) SendTemplateCardMessage(card *TemplateCard) error {
+	return c.sendMessage("template_card", card)
+}
+
+// sendMessage 发送消息底层接口
+func (c *WebhookBot) sendMessage(msgtype string, content interface{}) error {
+	_, err := c.execWebhookSend(reqWebhookSend{
+		Key:     c.Key,
+		MsgType: msgtype,
+		Content: content,
+	})
+	return err
+}
+
+const (
+	webhookMediaTypeFile  = "file"
+	webhookMediaTypeVoice = "voice"
+)
+
+// UploadFileMedia 上传群机器人文件素材
+//
+// 文件大小在 5B~20M 之间，素材 3 天内有效，仅限本机器人使用。
+func (c *WebhookBot) UploadFileMedia(media *Media) (*MediaUploadResult, error) {
+	return c.uploadMedia(webhookMediaTypeFile, media)
+}
+
+// UploadVoiceMedia 上传群机器人语音素材
+//
+// 文件大小在 5B~2M 之间，播放长度不超过 60s，仅支持 AMR 格式。
+func (c *WebhookBot) UploadVoiceMedia(media *Media) (*MediaUploadResult, error) {
+	return c.uploadMedia(webhookMediaTypeVoice, media)
+}
+
+func (c *WebhookBot) uploadMedia(typ string, media *Media) (*MediaUploadResult, error) {
+	resp, err := c.execWebhookUploadMedia(reqWebhookUploadMedia{
+		Key:   c.Key,
+		Type:  typ,
+		Media: media,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := resp.intoMediaUploadResult()
+	if err != nil {
+		return nil, err
+	}
+
+	return &obj, nil
+}
+
+//
+// API 接口
+//
+
+// execWebhookSend 群机器人发送消息
+func (c *WebhookBot) execWebhookSend(req reqWebhookSend) (respWebhookSend, error) {
+	var resp respWebhookSend
+	err := c.executeWebhookPost("/cgi-bin/webhook/send", req, &resp)
+	if err != nil {
+		return respWebhookSend{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respWebhookSend{}, bizErr
+	}
+
+	return resp, nil
+}
+
+// execWebhookUploadMedia 群机器人上传文件
+func (c *WebhookBot) execWebhookUploadMedia(req reqWebhookUploadMedia) (respMediaUpload, error) {
+	var resp respMediaUpload
+	err := c.executeWebhookMediaUpload("/cgi-bin/webhook/upload_media", req, &resp)
+	if err != nil {
+		return respMediaUpload{}, err
+	}
+	if bizErr := resp.TryIntoErr(); bizErr != nil {
+		return respMediaUpload{}, bizErr
+	}
+
+	return resp, nil
+}
+
+func (c *WebhookBot) composeWebhookURL(path string, req urlValuer) *url.URL {
+	base, err := url.Parse(c.opts.QYAPIHost)
+	if err != nil {
+		// TODO: error_chain
+		panic(fmt.Sprintf("qyapiHost invalid: host=%s err=%+v", c.opts.QYAPIHost, err))
+	}
+
+	base.Path = path
+	base.RawQuery = req.intoURLValues().Encode()
+
+	return base
+}
+
+func (c *WebhookBot) executeWebhookPost(path string, req reqWebhookSend, respObj interface{}) error {
+	urlStr := c.composeWebhookURL(path, req).String()
+
+	body, err := req.intoBody()
+	if err != nil {
+		// TODO: error_chain
+		return err
+	}
+
+	resp, err := c.opts.HTTP.Post(urlStr, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// TODO: error_chain
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	err = decoder.Decode(respObj)
+	if err != nil {
+		// TODO: error_chain
+		return err
+	}
+
+	return nil
+}
+
+func (c *WebhookBot) executeWebhookMediaUpload(
+	path string,
+	req reqWebhookUploadMedia,
+	respObj interface{},
+) error {
+	urlStr := c.composeWebhookURL(path, req).String()
+
+	m := req.getMedia()
+
+	// FIXME: use streaming upload to conserve memory!
+	buf := bytes.Buffer{}
+	mw := multipart.NewWriter(&buf)
+
+	err := m.writeTo(mw)
+	if err != nil {
+		return err
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.opts.HTTP.Post(urlStr, mw.FormDataContentType(), &buf)
+	if err != nil {
+		// TODO: error_chain
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	err = decoder.Decode(respObj)
+	if err != nil {
+		// TODO: error_chain
+		return err
+	}
+
+	return nil
+}
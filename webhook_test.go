@@ -0,0 +1,119 @@
+package workwx
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWebhookBot(t *testing.T) {
+	c.Convey("群机器人客户端", t, func() {
+		var lastPath string
+		var lastQuery string
+		var lastBody []byte
+		var lastContentType string
+		respBody := `{"errcode":0,"errmsg":"ok"}`
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			lastPath = r.URL.Path
+			lastQuery = r.URL.RawQuery
+			lastContentType = r.Header.Get("Content-Type")
+			lastBody, _ = ioutil.ReadAll(r.Body)
+			_, _ = rw.Write([]byte(respBody))
+		}))
+		defer server.Close()
+
+		bot := NewWebhookBot("test-key", WithQYAPIHost(server.URL))
+
+		decodeBody := func() map[string]interface{} {
+			var obj map[string]interface{}
+			err := json.Unmarshal(lastBody, &obj)
+			c.So(err, c.ShouldBeNil)
+			return obj
+		}
+
+		c.Convey("发送文本消息", func() {
+			err := bot.SendTextMessage("hello", []string{"foo", "@all"}, nil)
+			c.So(err, c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/webhook/send")
+			c.So(lastQuery, c.ShouldEqual, "key=test-key")
+
+			expected := map[string]interface{}{
+				"msgtype": "text",
+				"text": map[string]interface{}{
+					"content":        "hello",
+					"mentioned_list": []interface{}{"foo", "@all"},
+				},
+			}
+			c.So(decodeBody(), c.ShouldResemble, expected)
+		})
+
+		c.Convey("发送图片消息时自动计算 base64 与 md5", func() {
+			err := bot.SendImageMessage([]byte("not really a png"))
+			c.So(err, c.ShouldBeNil)
+
+			expected := map[string]interface{}{
+				"msgtype": "image",
+				"image": map[string]interface{}{
+					"base64": "bm90IHJlYWxseSBhIHBuZw==",
+					"md5":    "a4f84feadf4cad85108478e074357b33",
+				},
+			}
+			c.So(decodeBody(), c.ShouldResemble, expected)
+		})
+
+		c.Convey("过大的图片应该被拒绝", func() {
+			err := bot.SendImageMessage(make([]byte, webhookImageMaxBytes+1))
+			c.So(err, c.ShouldEqual, errWebhookImageTooLarge)
+		})
+
+		c.Convey("发送模板卡片消息", func() {
+			err := bot.SendTemplateCardMessage(&TemplateCard{
+				CardType:   TemplateCardTypeTextNotice,
+				MainTitle:  &TemplateCardMainTitle{Title: "title"},
+				CardAction: TemplateCardAction{Type: 1, URL: "https://example.com"},
+			})
+			c.So(err, c.ShouldBeNil)
+
+			expected := map[string]interface{}{
+				"msgtype": "template_card",
+				"template_card": map[string]interface{}{
+					"card_type":  "text_notice",
+					"main_title": map[string]interface{}{"title": "title"},
+					"card_action": map[string]interface{}{
+						"type": float64(1),
+						"url":  "https://example.com",
+					},
+				},
+			}
+			c.So(decodeBody(), c.ShouldResemble, expected)
+		})
+
+		c.Convey("业务错误应该被返回", func() {
+			respBody = `{"errcode":93000,"errmsg":"invalid webhook url"}`
+			err := bot.SendMarkdownMessage("**hi**")
+			c.So(err, c.ShouldNotBeNil)
+			clientErr, ok := err.(*WorkwxClientError)
+			c.So(ok, c.ShouldBeTrue)
+			c.So(clientErr.Code, c.ShouldEqual, 93000)
+		})
+
+		c.Convey("上传文件", func() {
+			respBody = `{"errcode":0,"errmsg":"ok","type":"file","media_id":"mid","created_at":"1380000000"}`
+			media, err := NewMediaFromBuffer("a.txt", []byte("hello world"))
+			c.So(err, c.ShouldBeNil)
+
+			result, err := bot.UploadFileMedia(media)
+			c.So(err, c.ShouldBeNil)
+			c.So(lastPath, c.ShouldEqual, "/cgi-bin/webhook/upload_media")
+			c.So(lastQuery, c.ShouldEqual, "key=test-key&type=file")
+			c.So(lastContentType, c.ShouldStartWith, "multipart/form-data")
+			c.So(result.MediaID, c.ShouldEqual, "mid")
+			c.So(result.CreatedAt.Unix(), c.ShouldEqual, 1380000000)
+		})
+	})
+}
@@ -0,0 +1,35 @@
+// Package workwxgin 为 go-workwx 的回调处理器提供 gin 适配。
+//
+// 本包是单独的 Go 模块，以免 SDK 本身依赖 gin。
+package workwxgin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xen0n/go-workwx"
+)
+
+type ginContextKey struct{}
+
+// Wrap 将回调处理器（如 workwx.HTTPHandler）适配为 gin.HandlerFunc
+//
+// 每个请求的 *gin.Context 会被放入请求的 context 中，回调时可用 GinContext 取出。
+// gin 会在请求结束后复用 *gin.Context，因此它只在请求处理期间有效，
+// 经 workwx.NewRxAsyncHandler 异步处理时取不到。
+func Wrap(h http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := workwx.WithRxRequestScopedValue(c.Request.Context(), ginContextKey{}, c)
+		h.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// GinContext 取出回调处理时 context 所属请求的 *gin.Context
+//
+// 如 ctx 并非来自 Wrap 适配的处理器，或处于异步处理器中（此时请求已经结束），则返回 nil, false。
+func GinContext(ctx context.Context) (*gin.Context, bool) {
+	c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c, ok
+}
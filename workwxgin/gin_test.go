@@ -0,0 +1,63 @@
+package workwxgin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	c "github.com/smartystreets/goconvey/convey"
+
+	"github.com/xen0n/go-workwx"
+)
+
+func TestWrap(t *testing.T) {
+	c.Convey("gin 适配器", t, func() {
+		gin.SetMode(gin.TestMode)
+
+		var gotCtx *gin.Context
+		var gotOK bool
+		inner := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			gotCtx, gotOK = GinContext(r.Context())
+			rw.WriteHeader(http.StatusTeapot)
+		})
+
+		router := gin.New()
+		router.Any("/callback", Wrap(inner))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/callback", nil)
+		router.ServeHTTP(rw, req)
+
+		c.So(rw.Code, c.ShouldEqual, http.StatusTeapot)
+		c.So(gotOK, c.ShouldBeTrue)
+		c.So(gotCtx, c.ShouldNotBeNil)
+		c.So(gotCtx.FullPath(), c.ShouldEqual, "/callback")
+
+		_, ok := GinContext(context.Background())
+		c.So(ok, c.ShouldBeFalse)
+
+		c.Convey("异步处理器中取不到 *gin.Context", func() {
+			var asyncOK bool
+			async := workwx.NewRxAsyncHandler(workwx.RxMessageHandlerFunc(func(ctx context.Context, _ *workwx.RxMessage) (workwx.RxReply, error) {
+				_, asyncOK = GinContext(ctx)
+				return nil, nil
+			}))
+
+			var syncOK bool
+			inner := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				_, syncOK = GinContext(r.Context())
+				_, _ = async.OnIncomingMessage(r.Context(), &workwx.RxMessage{})
+			})
+
+			router := gin.New()
+			router.Any("/callback", Wrap(inner))
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/callback", nil))
+
+			c.So(async.Shutdown(context.Background()), c.ShouldBeNil)
+			c.So(syncOK, c.ShouldBeTrue)
+			c.So(asyncOK, c.ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,10 @@
+module github.com/xen0n/go-workwx/workwxgin
+
+go 1.12
+
+require (
+	github.com/gin-gonic/gin v1.7.4
+	github.com/smartystreets/goconvey v1.6.4
+	github.com/xen0n/go-workwx v0.0.0-20261019160233-a02011dfd1e6
+	google.golang.org/appengine v1.6.1 // indirect
+)